@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/cache"
+	"github.com/spf13/cobra"
+)
+
+var FlagOlderThan time.Duration
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the lyrics cache",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached lyrics",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := cache.List(cache.Directory)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ARTIST\tTRACK\tLYRICS\tCACHED")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				e.Artist, e.Track, entryKind(e), e.Time.Format(time.DateTime))
+		}
+		return w.Flush()
+	},
+}
+
+var cacheShowCmd = &cobra.Command{
+	Use:   "show <artist> <track>",
+	Short: "Print cached lyrics",
+	Args:  cobra.ExactArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := cache.Get(cache.Directory, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return errors.New("not in cache")
+		}
+		if !entry.Found() {
+			return errors.New("no lyrics found for this track")
+		}
+		if lyrics.Instrumental(entry.Lyrics.Lines) {
+			fmt.Println("Instrumental")
+			return nil
+		}
+
+		if !entry.Lyrics.Synced {
+			for _, line := range entry.Lyrics.Lines {
+				fmt.Println(line.Words)
+			}
+			return nil
+		}
+		return lyrics.WriteLRC(os.Stdout, entryLRC(entry))
+	},
+}
+
+var cacheRmCmd = &cobra.Command{
+	Use:   "rm <artist> <track>",
+	Short: "Remove lyrics from the cache",
+	Args:  cobra.ExactArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cache.Remove(cache.Directory, args[0], args[1])
+		if errors.Is(err, fs.ErrNotExist) {
+			return errors.New("not in cache")
+		}
+		return err
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old lyrics from the cache",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := cache.Prune(cache.Directory, FlagOlderThan)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d entries\n", removed)
+		return nil
+	},
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export <dir>",
+	Short: "Export cached synced lyrics as .lrc files",
+	Args:  cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := cache.List(cache.Directory)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(args[0], os.ModePerm); err != nil {
+			return err
+		}
+
+		var exported, skipped int
+		for _, e := range entries {
+			if !e.Found() || lyrics.Instrumental(e.Lyrics.Lines) {
+				continue
+			}
+			if !e.Lyrics.Synced {
+				skipped++
+				continue
+			}
+			if err := exportEntry(args[0], e); err != nil {
+				return err
+			}
+			exported++
+		}
+		fmt.Printf("Exported %d files, skipped %d without timing\n", exported, skipped)
+		return nil
+	},
+}
+
+var filenameReplacer = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_",
+	"?", "_", "\"", "_", "<", "_", ">", "_", "|", "_",
+)
+
+func exportEntry(dir string, e *cache.Entry) error {
+	name := filenameReplacer.Replace(e.Artist + " - " + e.Track)
+	f, err := os.Create(filepath.Join(dir, name+".lrc"))
+	if err != nil {
+		return err
+	}
+	if err := lyrics.WriteLRC(f, entryLRC(e)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func entryLRC(e *cache.Entry) *lyrics.LRC {
+	return &lyrics.LRC{
+		Artist: e.Artist,
+		Title:  e.Track,
+		Lines:  e.Lyrics.Lines,
+	}
+}
+
+func entryKind(e *cache.Entry) string {
+	switch {
+	case !e.Found():
+		return "not found"
+	case lyrics.Instrumental(e.Lyrics.Lines):
+		return "instrumental"
+	case e.Lyrics.Synced:
+		return "synced"
+	default:
+		return "plain"
+	}
+}
+
+func init() {
+	cachePruneCmd.Flags().DurationVar(&FlagOlderThan, "older-than", 30*24*time.Hour, "remove entries older than this")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheShowCmd)
+	cacheCmd.AddCommand(cacheRmCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheExportCmd)
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/raitonoberu/sptlrx/config"
-	"github.com/raitonoberu/sptlrx/lyrics"
 	"github.com/raitonoberu/sptlrx/pool"
 
 	"github.com/muesli/reflow/wordwrap"
@@ -13,6 +16,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var FlagJSON bool
+
 var pipeCmd = &cobra.Command{
 	Use:   "pipe",
 	Short: "Start printing the current lines to stdout",
@@ -30,17 +35,70 @@ var pipeCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("couldn't load provider: %w", err)
 		}
+		defer provider.Close()
+
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
 
 		ch := make(chan pool.Update)
-		go pool.Listen(player, provider, conf, ch)
+		go pool.Listen(ctx, player, provider, conf, ch)
 
 		for update := range ch {
-			printUpdate(update, conf)
+			if FlagJSON {
+				printJSON(update, conf)
+			} else {
+				printUpdate(update, conf)
+			}
 		}
 		return nil
 	},
 }
 
+// pipeState is printed for every update with --json.
+type pipeState struct {
+	Line         string `json:"line"`
+	Index        int    `json:"index"`
+	Playing      bool   `json:"playing"`
+	Synced       bool   `json:"synced"`
+	Instrumental bool   `json:"instrumental"`
+	Loading      bool   `json:"loading"`
+	Source       string `json:"source,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Artist       string `json:"artist,omitempty"`
+	Title        string `json:"title,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func printJSON(update pool.Update, conf *config.Config) {
+	var state pipeState
+	if update.Err != nil {
+		if conf.IgnoreErrors {
+			return
+		}
+		state.Error = update.Err.Error()
+	} else {
+		state.Index = update.Index
+		state.Playing = update.Playing
+		state.Instrumental = update.Instrumental
+		state.Loading = update.Loading
+		if l := update.Lyrics; l != nil {
+			if l.Synced && !update.Instrumental {
+				state.Line = l.Lines[update.Index].Words
+			}
+			state.Synced = l.Synced
+			state.Source = l.Source
+			state.Language = l.Language
+			state.Artist = l.Artist
+			state.Title = l.Title
+			state.URL = l.URL
+		}
+	}
+
+	b, _ := json.Marshal(state)
+	fmt.Println(string(b))
+}
+
 func printUpdate(update pool.Update, conf *config.Config) {
 	if update.Err != nil {
 		if !conf.IgnoreErrors {
@@ -48,11 +106,16 @@ func printUpdate(update pool.Update, conf *config.Config) {
 		}
 		return
 	}
-	if update.Lines == nil || !lyrics.Timesynced(update.Lines) {
+	var line string
+	switch {
+	case update.Instrumental:
+		line = conf.InstrumentalText
+	case update.Lyrics == nil || !update.Lyrics.Synced:
 		fmt.Println("")
 		return
+	default:
+		line = update.Lines[update.Index].Words
 	}
-	line := update.Lines[update.Index].Words
 	if conf.Pipe.Length == 0 {
 		fmt.Println(line)
 		return
@@ -75,3 +138,7 @@ func printUpdate(update pool.Update, conf *config.Config) {
 		fmt.Println(strings.Split(s, "\n")[0] + "...")
 	}
 }
+
+func init() {
+	pipeCmd.Flags().BoolVar(&FlagJSON, "json", false, "print updates as JSON objects with the lyrics source")
+}
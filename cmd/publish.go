@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/lrclib"
+	"github.com/spf13/cobra"
+)
+
+var FlagAddress string
+
+var publishCmd = &cobra.Command{
+	Use:   "publish <file.lrc>",
+	Short: "Publish synced lyrics to LRCLIB",
+	Long: "Publish synced lyrics to LRCLIB. The file must have [ar:], [ti:], [al:]\n" +
+		"and [length:] tags, which are used as the artist, track, album and duration.",
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("couldn't load config: %w", err)
+		}
+		if cmd.Flags().Changed("address") {
+			conf.Lrclib.Address = FlagAddress
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		lrc, err := lyrics.ParseLRC(f)
+		if err != nil {
+			return fmt.Errorf("couldn't parse file: %w", err)
+		}
+		track, err := publishTrack(lrc)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Publishing %s - %s...\n", track.ArtistName, track.TrackName)
+		client := lrclib.New(conf.Lrclib.Address, conf.Lrclib.UserAgent)
+		if err := client.Publish(cmd.Context(), track); err != nil {
+			return err
+		}
+
+		fmt.Println("Success! Thank you for contributing")
+		return nil
+	},
+}
+
+func publishTrack(lrc *lyrics.LRC) (lrclib.Track, error) {
+	var missing []string
+	if lrc.Artist == "" {
+		missing = append(missing, "[ar:]")
+	}
+	if lrc.Title == "" {
+		missing = append(missing, "[ti:]")
+	}
+	if lrc.Album == "" {
+		missing = append(missing, "[al:]")
+	}
+	if lrc.Length == 0 {
+		missing = append(missing, "[length:]")
+	}
+	if len(missing) != 0 {
+		return lrclib.Track{}, fmt.Errorf("missing tags: %s", strings.Join(missing, ", "))
+	}
+	if !lyrics.Timesynced(lrc.Lines) {
+		return lrclib.Track{}, errors.New("no synced lyrics in the file")
+	}
+
+	plain := make([]string, len(lrc.Lines))
+	for i, line := range lrc.Lines {
+		plain[i] = line.Words
+	}
+
+	// offset is already applied to the lines
+	var synced strings.Builder
+	err := lyrics.WriteLRC(&synced, &lyrics.LRC{
+		Precision: lrc.Precision,
+		Lines:     lrc.Lines,
+	})
+	if err != nil {
+		return lrclib.Track{}, err
+	}
+
+	return lrclib.Track{
+		TrackName:    lrc.Title,
+		ArtistName:   lrc.Artist,
+		AlbumName:    lrc.Album,
+		Duration:     lrc.Length / 1000,
+		PlainLyrics:  strings.TrimSpace(strings.Join(plain, "\n")),
+		SyncedLyrics: synced.String(),
+	}, nil
+}
+
+func init() {
+	publishCmd.Flags().StringVar(&FlagAddress, "address", lrclib.DefaultAddress, "address of the LRCLIB instance (overrides config)")
+}
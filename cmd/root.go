@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -10,8 +11,11 @@ import (
 	"github.com/raitonoberu/sptlrx/lyrics"
 	"github.com/raitonoberu/sptlrx/player"
 	"github.com/raitonoberu/sptlrx/pool"
+	"github.com/raitonoberu/sptlrx/services/cache"
 	"github.com/raitonoberu/sptlrx/services/local"
 	"github.com/raitonoberu/sptlrx/services/lrclib"
+	"github.com/raitonoberu/sptlrx/services/lrclibdb"
+	"github.com/raitonoberu/sptlrx/services/sidecar"
 	"github.com/raitonoberu/sptlrx/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -59,9 +63,13 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("couldn't load provider: %w", err)
 		}
+		defer provider.Close()
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
 
 		ch := make(chan pool.Update)
-		go pool.Listen(player, provider, conf, ch)
+		go pool.Listen(ctx, player, provider, conf, ch)
 
 		_, err = tea.NewProgram(
 			&ui.Model{
@@ -70,6 +78,11 @@ var rootCmd = &cobra.Command{
 			},
 			tea.WithAltScreen(),
 		).Run()
+
+		// wait for the player to be closed
+		cancel()
+		for range ch {
+		}
 		return err
 	},
 }
@@ -122,11 +135,47 @@ func loadPlayer(conf *config.Config) (player.Player, error) {
 	return player, nil
 }
 
-func loadProvider(conf *config.Config) (lyrics.Provider, error) {
-	if conf.Local.Folder != "" {
-		return local.New(conf.Local.Folder)
+func loadProvider(conf *config.Config) (*lyrics.Chain, error) {
+	sources := make([]lyrics.Source, 0, len(conf.Providers))
+	// close the sources created so far if one fails
+	fail := func(err error) (*lyrics.Chain, error) {
+		lyrics.NewChain(sources...).Close()
+		return nil, err
+	}
+	for _, name := range conf.Providers {
+		var provider lyrics.Provider
+		switch name {
+		case "sidecar":
+			provider = sidecar.New(conf.Mpd.MusicDirectory)
+		case "local":
+			if conf.Local.Folder == "" {
+				continue
+			}
+			local, err := local.New(conf.Local.Folder)
+			if err != nil {
+				return fail(err)
+			}
+			provider = local
+		case "lrclib":
+			provider = lrclib.New(conf.Lrclib.Address, conf.Lrclib.UserAgent)
+			if conf.Cache.Enabled {
+				provider = cache.New(provider, cache.Directory, conf.Cache.TTL, conf.Cache.MissTTL)
+			}
+		case "lrclibdb":
+			if conf.Lrclib.Database == "" {
+				continue
+			}
+			db, err := lrclibdb.New(conf.Lrclib.Database)
+			if err != nil {
+				return fail(err)
+			}
+			provider = db
+		default:
+			return fail(fmt.Errorf("unknown provider: \"%s\"", name))
+		}
+		sources = append(sources, lyrics.Source{Name: name, Provider: provider})
 	}
-	return lrclib.New(), nil
+	return lyrics.NewChain(sources...), nil
 }
 
 func parseStyleFlag(value string) config.Style {
@@ -169,6 +218,8 @@ func init() {
 
 	rootCmd.AddCommand(pipeCmd)
 	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(publishCmd)
 }
 
 func Execute() {
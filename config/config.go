@@ -6,6 +6,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/raitonoberu/sptlrx/player"
 	"github.com/raitonoberu/sptlrx/services/browser"
@@ -39,6 +40,12 @@ type Config struct {
 	TimerInterval  int    `default:"200" yaml:"timerInterval"`
 	UpdateInterval int    `default:"2000" yaml:"updateInterval"`
 
+	Timeout time.Duration `default:"10s" yaml:"timeout"`
+
+	InstrumentalText string `default:"♪ Instrumental ♪" yaml:"instrumentalText"`
+
+	Providers []string `default:"[\"sidecar\", \"local\", \"lrclib\"]" yaml:"providers"`
+
 	Style struct {
 		HAlignment string `default:"center" yaml:"hAlignment"`
 
@@ -53,8 +60,9 @@ type Config struct {
 	} `yaml:"pipe"`
 
 	Mpd struct {
-		Address  string `default:"127.0.0.1:6600" yaml:"address"`
-		Password string `yaml:"password"`
+		Address        string `default:"127.0.0.1:6600" yaml:"address"`
+		Password       string `yaml:"password"`
+		MusicDirectory string `yaml:"musicDirectory"`
 	} `yaml:"mpd"`
 
 	Mopidy struct {
@@ -72,6 +80,18 @@ type Config struct {
 	Local struct {
 		Folder string `yaml:"folder"`
 	} `yaml:"local"`
+
+	Lrclib struct {
+		Address   string `default:"https://lrclib.net" yaml:"address"`
+		UserAgent string `yaml:"userAgent"`
+		Database  string `yaml:"database"`
+	} `yaml:"lrclib"`
+
+	Cache struct {
+		Enabled bool          `default:"true" yaml:"enabled"`
+		TTL     time.Duration `default:"0s" yaml:"ttl"`
+		MissTTL time.Duration `default:"24h" yaml:"missTtl"`
+	} `yaml:"cache"`
 }
 
 func New() *Config {
@@ -0,0 +1,75 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Source is a named lyrics provider.
+type Source struct {
+	Name string
+	Provider
+}
+
+// NewChain returns a provider that tries the sources in order.
+func NewChain(sources ...Source) *Chain {
+	return &Chain{sources: sources}
+}
+
+// Chain implements Provider. It returns the first time-synced lyrics
+// (or instrumental mark) found, or the first plain ones if no source
+// has synced lyrics. The name of the source is set if the provider
+// doesn't set it.
+type Chain struct {
+	sources []Source
+}
+
+// Close closes the sources that implement io.Closer.
+func (c *Chain) Close() error {
+	var errs []error
+	for _, s := range c.sources {
+		if closer, ok := s.Provider.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Chain) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	var (
+		result *Lyrics
+		errs   []error
+	)
+	for _, s := range c.sources {
+		if ctx.Err() != nil {
+			// the rest would fail the same way
+			errs = append(errs, ctx.Err())
+			break
+		}
+		l, err := s.Lyrics(ctx, query)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if l == nil || len(l.Lines) == 0 {
+			continue
+		}
+		if l.Source == "" {
+			l.Source = s.Name
+		}
+		// instrumental tracks have nothing to sync
+		if l.Synced || Instrumental(l.Lines) {
+			result = l
+			break
+		}
+		if result == nil {
+			result = l
+		}
+	}
+
+	if result == nil {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
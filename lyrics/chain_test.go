@@ -0,0 +1,172 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type providerFunc func(context.Context, Query) (*Lyrics, error)
+
+func (f providerFunc) Lyrics(ctx context.Context, q Query) (*Lyrics, error) {
+	return f(ctx, q)
+}
+
+func static(lines []Line, err error) Provider {
+	return providerFunc(func(context.Context, Query) (*Lyrics, error) {
+		if lines == nil {
+			return nil, err
+		}
+		return &Lyrics{Lines: lines, Synced: Timesynced(lines)}, err
+	})
+}
+
+func TestChain(t *testing.T) {
+	var (
+		plain  = []Line{{Words: "plain"}, {Words: "lyrics"}}
+		synced = []Line{{Time: 0, Words: "synced"}, {Time: 1000, Words: "lyrics"}}
+		errFoo = errors.New("foo")
+	)
+
+	tests := []struct {
+		name           string
+		sources        []Source
+		expected       []Line
+		expectedSource string
+		expectedErr    error
+	}{
+		{
+			name: "first synced",
+			sources: []Source{
+				{"a", static(nil, nil)},
+				{"b", static(synced, nil)},
+				{"c", static(plain, nil)},
+			},
+			expected:       synced,
+			expectedSource: "b",
+		},
+		{
+			name: "synced over plain",
+			sources: []Source{
+				{"a", static(plain, nil)},
+				{"b", static(nil, errFoo)},
+				{"c", static(synced, nil)},
+			},
+			expected:       synced,
+			expectedSource: "c",
+		},
+		{
+			name: "plain if no synced",
+			sources: []Source{
+				{"a", static(nil, errFoo)},
+				{"b", static(plain, nil)},
+				{"c", static(nil, nil)},
+			},
+			expected:       plain,
+			expectedSource: "b",
+		},
+		{
+			name: "instrumental over plain",
+			sources: []Source{
+				{"a", static(plain, nil)},
+				{"b", static([]Line{{}}, nil)},
+				{"c", static(synced, nil)},
+			},
+			expected:       []Line{{}},
+			expectedSource: "b",
+		},
+		{
+			name: "errors if nothing found",
+			sources: []Source{
+				{"a", static(nil, errFoo)},
+				{"b", static(nil, nil)},
+			},
+			expectedErr: errFoo,
+		},
+		{
+			name: "nothing found",
+			sources: []Source{
+				{"a", static(nil, nil)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain(tt.sources...)
+			result, err := chain.Lyrics(context.Background(), Query{})
+			if !errors.Is(err, tt.expectedErr) || (tt.expectedErr == nil && err != nil) {
+				t.Errorf("Lyrics() error = %v; want %v", err, tt.expectedErr)
+			}
+			var (
+				lines  []Line
+				source string
+			)
+			if result != nil {
+				lines, source = result.Lines, result.Source
+			}
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("Lyrics() = %+v; want %+v", lines, tt.expected)
+			}
+			if source != tt.expectedSource {
+				t.Errorf("Lyrics().Source = %q; want %q", source, tt.expectedSource)
+			}
+		})
+	}
+}
+
+func TestChainCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var called bool
+	chain := NewChain(
+		Source{"a", providerFunc(func(context.Context, Query) (*Lyrics, error) {
+			// the track has changed
+			cancel()
+			return nil, nil
+		})},
+		Source{"b", providerFunc(func(context.Context, Query) (*Lyrics, error) {
+			called = true
+			return nil, nil
+		})},
+	)
+
+	result, err := chain.Lyrics(ctx, Query{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Lyrics() error = %v; want %v", err, context.Canceled)
+	}
+	if result != nil {
+		t.Errorf("Lyrics() = %+v; want nil", result)
+	}
+	if called {
+		t.Error("Lyrics() called the provider after the context was cancelled")
+	}
+}
+
+type closingProvider struct {
+	Provider
+	closed bool
+}
+
+func (p *closingProvider) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestChainClose(t *testing.T) {
+	a := &closingProvider{Provider: static(nil, nil)}
+	b := &closingProvider{Provider: static(nil, nil)}
+	chain := NewChain(
+		Source{"a", a},
+		Source{"plain", static(nil, nil)},
+		Source{"b", b},
+	)
+
+	if err := chain.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.closed || !b.closed {
+		t.Errorf("Close() closed a: %v, b: %v; want both", a.closed, b.closed)
+	}
+}
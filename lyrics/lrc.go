@@ -0,0 +1,194 @@
+package lyrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LRC is a parsed LRC document.
+type LRC struct {
+	Artist string
+	Title  string
+	Album  string
+	// Length of the track in ms.
+	Length int
+	// Offset in ms. It is already applied to the lines,
+	// so their times may be negative.
+	Offset int
+	// Precision is the number of digits in the fraction
+	// of timestamps (1-3). 2 is used if not set.
+	Precision int
+	// Tags are the rest of the ID tags, in order of appearance.
+	Tags []Tag
+
+	Lines []Line
+}
+
+// Tag is an LRC ID tag like "[by: Author]".
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// ParseLRC parses an LRC document. Lines are sorted by time.
+func ParseLRC(r io.Reader) (*LRC, error) {
+	lrc := &LRC{Lines: []Line{}}
+
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		line = strings.TrimSpace(line)
+
+		if IsTimestampLine(line) {
+			if lrc.Precision == 0 {
+				lrc.Precision = timestampPrecision(line)
+			}
+			lrc.Lines = append(lrc.Lines, ParseLrcLines(line)...)
+			continue
+		}
+		if key, value, ok := parseTag(line); ok {
+			lrc.setTag(key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if lrc.Offset != 0 {
+		applyOffset(lrc.Lines, lrc.Offset)
+	}
+	SortLines(lrc.Lines)
+	return lrc, nil
+}
+
+func (l *LRC) setTag(key, value string) {
+	switch key {
+	case "ar":
+		l.Artist = value
+	case "ti":
+		l.Title = value
+	case "al":
+		l.Album = value
+	case "length":
+		l.Length = parseLength(value)
+	case "offset":
+		l.Offset, _ = strconv.Atoi(strings.TrimPrefix(value, "+"))
+	default:
+		l.Tags = append(l.Tags, Tag{Key: key, Value: value})
+	}
+}
+
+// WriteLRC writes the document in LRC format. Timestamps are written
+// without the offset applied, so that parsing the output gives the same
+// document.
+func WriteLRC(w io.Writer, lrc *LRC) error {
+	bw := bufio.NewWriter(w)
+
+	writeTag := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(bw, "[%s:%s]\n", key, value)
+		}
+	}
+	writeTag("ar", lrc.Artist)
+	writeTag("ti", lrc.Title)
+	writeTag("al", lrc.Album)
+	if lrc.Length != 0 {
+		writeTag("length", formatLength(lrc.Length))
+	}
+	for _, tag := range lrc.Tags {
+		writeTag(tag.Key, tag.Value)
+	}
+	if lrc.Offset > 0 {
+		writeTag("offset", "+"+strconv.Itoa(lrc.Offset))
+	} else if lrc.Offset < 0 {
+		writeTag("offset", strconv.Itoa(lrc.Offset))
+	}
+
+	precision := lrc.Precision
+	if precision < 1 || precision > 3 {
+		precision = 2
+	}
+	for _, line := range lrc.Lines {
+		bw.WriteByte('[')
+		bw.WriteString(formatTimestamp(line.Time+lrc.Offset, precision))
+		bw.WriteByte(']')
+
+		if len(line.Syllables) == 0 {
+			bw.WriteString(line.Words)
+		}
+		for _, s := range line.Syllables {
+			bw.WriteByte('<')
+			bw.WriteString(formatTimestamp(s.Time+lrc.Offset, precision))
+			bw.WriteByte('>')
+			bw.WriteString(s.Text)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// parseTag parses an ID tag like "[ar: Artist]".
+func parseTag(line string) (key, value string, ok bool) {
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(line[1:len(line)-1], ":")
+	if !ok || key == "" {
+		return "", "", false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '#' {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(key), strings.TrimSpace(value), true
+}
+
+// timestampPrecision returns the number of fraction digits
+// in the first timestamp of the line.
+func timestampPrecision(line string) int {
+	closeBracket := strings.IndexByte(line, ']')
+	_, precision, _ := parseTimestampPrecision(line[1:closeBracket])
+	return precision
+}
+
+// formatTimestamp formats ms like "mm:ss.xx" with the given precision.
+func formatTimestamp(ms, precision int) string {
+	ms = max(ms, 0)
+	frac := ms % 1000
+	for i := precision; i < 3; i++ {
+		frac /= 10
+	}
+	return fmt.Sprintf("%02d:%02d.%0*d", ms/60000, ms/1000%60, precision, frac)
+}
+
+// formatLength formats the length of the track like "03:25".
+func formatLength(ms int) string {
+	if ms%1000 != 0 {
+		return formatTimestamp(ms, 3)
+	}
+	return fmt.Sprintf("%02d:%02d", ms/60000, ms/1000%60)
+}
+
+// parseLength parses the length of the track like "03:25" into ms.
+func parseLength(s string) int {
+	t, _ := parseTimestamp(s)
+	return t
+}
+
+// applyOffset shifts the lines according to the [offset:] tag.
+// A positive offset makes the lyrics appear sooner. Times are not
+// clamped to 0, so that WriteLRC can restore the original ones.
+func applyOffset(lines []Line, offset int) {
+	for i := range lines {
+		lines[i].Time -= offset
+		// syllables may be shared between repeated lines
+		lines[i].Syllables = shiftSyllables(lines[i].Syllables, -offset)
+	}
+}
@@ -0,0 +1,125 @@
+package lyrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLRC(t *testing.T) {
+	const input = "\ufeff[ar: Artist Name]\r\n" +
+		"[ti:Song Title]\n" +
+		"[al: Album]\n" +
+		"[length: 03:25]\n" +
+		"[offset:+500]\n" +
+		"\n" +
+		"[00:00.20]first\n" +
+		"[00:12.00][00:02.00]chorus\n" +
+		"[00:05.00]<00:05.00>second <00:05.50>line\n" +
+		"not a timestamp line\n"
+
+	lrc, err := ParseLRC(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &LRC{
+		Artist:    "Artist Name",
+		Title:     "Song Title",
+		Album:     "Album",
+		Length:    205000,
+		Offset:    500,
+		Precision: 2,
+		Lines: []Line{
+			{Time: -300, Words: "first"},
+			{Time: 1500, Words: "chorus"},
+			{Time: 4500, Words: "second line", Syllables: []Syllable{
+				{Time: 4500, Text: "second "},
+				{Time: 5000, Text: "line"},
+			}},
+			{Time: 11500, Words: "chorus"},
+		},
+	}
+	if !reflect.DeepEqual(lrc, expected) {
+		t.Errorf("ParseLRC() = %+v; want %+v", lrc, expected)
+	}
+}
+
+func TestParseLRCNegativeOffset(t *testing.T) {
+	lrc, err := ParseLRC(strings.NewReader("[offset:-250]\n[00:01.00]line\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lrc.Offset != -250 || lrc.Lines[0].Time != 1250 {
+		t.Errorf("ParseLRC() = %+v; want offset -250 and time 1250", lrc)
+	}
+}
+
+func TestWriteLRC(t *testing.T) {
+	inputs := []string{
+		"[ar:Artist]\n" +
+			"[ti:Title]\n" +
+			"[al:Album]\n" +
+			"[length:03:25]\n" +
+			"[by:Someone]\n" +
+			"[re:Editor]\n" +
+			"[offset:-100]\n" +
+			"[00:01.000]first\n" +
+			"[00:02.500]<00:02.500>second <00:03.250>line<00:04.000>\n" +
+			"[00:05.000]\n" +
+			"[100:00.050]last\n",
+		// lines before the offset
+		"[offset:+500]\n" +
+			"[00:00.20]<00:00.20>first <00:00.80>line\n" +
+			"[00:01.00]second\n",
+	}
+
+	for _, input := range inputs {
+		lrc, err := ParseLRC(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b strings.Builder
+		if err := WriteLRC(&b, lrc); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != input {
+			t.Errorf("WriteLRC() = %q; want %q", b.String(), input)
+		}
+
+		again, err := ParseLRC(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(lrc, again) {
+			t.Errorf("round trip = %+v; want %+v", again, lrc)
+		}
+	}
+}
+
+func TestWriteLRCPrecision(t *testing.T) {
+	lrc := &LRC{Lines: []Line{{Time: 61234, Words: "line"}}}
+
+	tests := []struct {
+		precision int
+		expected  string
+	}{
+		{0, "[01:01.23]line\n"},
+		{1, "[01:01.2]line\n"},
+		{2, "[01:01.23]line\n"},
+		{3, "[01:01.234]line\n"},
+	}
+
+	for _, tt := range tests {
+		lrc.Precision = tt.precision
+
+		var b strings.Builder
+		if err := WriteLRC(&b, lrc); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.expected {
+			t.Errorf("WriteLRC() with precision %d = %q; want %q", tt.precision, b.String(), tt.expected)
+		}
+	}
+}
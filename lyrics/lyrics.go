@@ -1,53 +1,283 @@
 package lyrics
 
 import (
+	"context"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// Provider returns the lyrics for the track, or nil if there are none.
+// It should give up when the context is done.
 type Provider interface {
-	Lyrics(artist, track string) ([]Line, error)
+	Lyrics(ctx context.Context, query Query) (*Lyrics, error)
+}
+
+// Lyrics are the lines of the track with the information about them.
+type Lyrics struct {
+	Lines []Line `json:"lines"`
+	// Source is the name of the provider the lyrics came from.
+	Source string `json:"source,omitempty"`
+	// Synced means that the lines are time-synced.
+	Synced bool `json:"synced"`
+	// Language of the lyrics, if known.
+	Language string `json:"language,omitempty"`
+	// Artist and Title of the track the lyrics were found for, if known.
+	Artist string `json:"artist,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// URL of the lyrics, if any.
+	URL string `json:"url,omitempty"`
+}
+
+// Query describes the track to find lyrics for.
+type Query struct {
+	Artist string
+	Track  string
+	Album  string
+	// Duration of the track in ms, 0 if unknown.
+	Duration int
+	// File is the path or URI of the audio file, if known.
+	File string
 }
 
 type Line struct {
 	Time  int    `json:"time"`
 	Words string `json:"words"`
+	// Syllables hold word-level timing (Enhanced LRC), if any.
+	Syllables []Syllable `json:"syllables,omitempty"`
+	// Singer of the line (TTML agent), if known.
+	Singer string `json:"singer,omitempty"`
+}
+
+// Syllable is a part of the line that starts at the given time.
+type Syllable struct {
+	Time int    `json:"time"`
+	Text string `json:"text"`
+}
+
+// Progress returns the number of syllables that have started at the position.
+func (l Line) Progress(position int) int {
+	var n int
+	for n < len(l.Syllables) && l.Syllables[n].Time <= position {
+		n++
+	}
+	return n
 }
 
 func Timesynced(lines []Line) bool {
 	return len(lines) > 1 && lines[1].Time != 0
 }
 
+// Instrumental reports whether the lyrics mark an instrumental track,
+// which is usually done with a single empty line.
+func Instrumental(lines []Line) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	for _, l := range lines {
+		if l.Words != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsTimestampLine reports whether the line starts with a timestamp.
 func IsTimestampLine(line string) bool {
-	if len(line) < 10 {
+	if !strings.HasPrefix(line, "[") {
+		return false
+	}
+	closeBracket := strings.IndexByte(line, ']')
+	if closeBracket == -1 {
 		return false
 	}
-	return line[0] == '[' &&
-		line[1] >= '0' && line[1] <= '9' &&
-		line[2] >= '0' && line[2] <= '9' &&
-		line[3] == ':' &&
-		line[6] == '.' &&
-		strings.IndexByte(line, ']') > 6
+	_, ok := parseTimestamp(line[1:closeBracket])
+	return ok
 }
 
 func ParseLrcLine(line string) Line {
-	m, _ := strconv.Atoi(line[1:3])
-	s, _ := strconv.Atoi(line[4:6])
+	return ParseLrcLines(line)[0]
+}
 
-	closeBracket := strings.IndexByte(line, ']')
+// ParseLrcLines parses a line with one or more leading timestamps,
+// like "[00:12.00][01:40.50]Chorus", into a line for each timestamp.
+func ParseLrcLines(line string) []Line {
+	var times []int
+	for strings.HasPrefix(line, "[") {
+		closeBracket := strings.IndexByte(line, ']')
+		if closeBracket == -1 {
+			break
+		}
+		time, ok := parseTimestamp(line[1:closeBracket])
+		if !ok {
+			break
+		}
+		times = append(times, time)
+		line = line[closeBracket+1:]
+	}
+	if len(times) == 0 {
+		// should not happen for lines passing IsTimestampLine
+		times = []int{0}
+	}
+
+	words := strings.TrimSpace(line)
+	words, syllables := parseSyllables(times[0], words)
+
+	result := make([]Line, len(times))
+	for i, time := range times {
+		result[i] = Line{
+			Time:      time,
+			Words:     words,
+			Syllables: shiftSyllables(syllables, time-times[0]),
+		}
+	}
+	return result
+}
 
-	msStr := line[7:closeBracket]
-	ms, _ := strconv.Atoi(msStr)
-	if len(msStr) == 2 {
-		ms *= 10
-	} else if len(msStr) == 1 {
-		ms *= 100
+// SortLines sorts the lines by time, keeping the order of equal ones.
+func SortLines(lines []Line) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time < lines[j].Time
+	})
+}
+
+// parseTimestamp parses a timestamp into ms. Supported formats are
+// "[hh:]m:ss[.xx]" and "[hh:]m:ss:xx", where the fraction has 1-3 digits.
+func parseTimestamp(s string) (int, bool) {
+	ms, _, ok := parseTimestampPrecision(s)
+	return ms, ok
+}
+
+// parseTimestampPrecision is like parseTimestamp, but also returns
+// the number of digits in the fraction.
+func parseTimestampPrecision(s string) (ms, precision int, ok bool) {
+	parts := strings.Split(s, ":")
+
+	var (
+		frac    string
+		hasFrac bool
+	)
+	if sec, f, found := strings.Cut(parts[len(parts)-1], "."); found {
+		parts[len(parts)-1] = sec
+		frac, hasFrac = f, true
+	} else if len(parts) == 3 || len(parts) == 4 {
+		// ':' is used as a fraction separator
+		frac, hasFrac = parts[len(parts)-1], true
+		parts = parts[:len(parts)-1]
 	}
 
-	words := strings.TrimSpace(line[closeBracket+1:])
+	var h, m, sec int
+	switch len(parts) {
+	case 2:
+		m, ok = parseNumber(parts[0], 1, 0)
+		if !ok {
+			return 0, 0, false
+		}
+	case 3:
+		h, ok = parseNumber(parts[0], 1, 0)
+		if !ok {
+			return 0, 0, false
+		}
+		m, ok = parseNumber(parts[1], 1, 2)
+		if !ok || m >= 60 {
+			return 0, 0, false
+		}
+	default:
+		return 0, 0, false
+	}
+	sec, ok = parseNumber(parts[len(parts)-1], 1, 2)
+	if !ok || sec >= 60 {
+		return 0, 0, false
+	}
 
-	return Line{
-		Time:  m*60*1000 + s*1000 + ms,
-		Words: words,
+	if hasFrac {
+		ms, ok = parseNumber(frac, 1, 3)
+		if !ok {
+			return 0, 0, false
+		}
+		for i := len(frac); i < 3; i++ {
+			ms *= 10
+		}
+	}
+
+	return ((h*60+m)*60+sec)*1000 + ms, len(frac), true
+}
+
+// parseNumber parses a non-negative number of minLen-maxLen digits.
+// maxLen of 0 means no limit.
+func parseNumber(s string, minLen, maxLen int) (int, bool) {
+	if len(s) < minLen || (maxLen != 0 && len(s) > maxLen) {
+		return 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// parseSyllables splits enhanced LRC words like "<00:01.00>foo <00:01.50>bar"
+// into syllables. It returns the words without timestamps.
+func parseSyllables(time int, words string) (string, []Syllable) {
+	if strings.IndexByte(words, '<') == -1 {
+		return words, nil
+	}
+
+	var (
+		syllables []Syllable
+		current   = Syllable{Time: time}
+		found     bool
+		rest      = words
+	)
+	for {
+		open := strings.IndexByte(rest, '<')
+		if open == -1 {
+			break
+		}
+		close := strings.IndexByte(rest[open:], '>')
+		if close == -1 {
+			break
+		}
+		close += open
+
+		t, ok := parseTimestamp(rest[open+1 : close])
+		if !ok {
+			// not a timestamp, keep it as text
+			current.Text += rest[:close+1]
+			rest = rest[close+1:]
+			continue
+		}
+		current.Text += rest[:open]
+		if found || current.Text != "" {
+			syllables = append(syllables, current)
+		}
+		current = Syllable{Time: t}
+		found = true
+		rest = rest[close+1:]
+	}
+	if !found {
+		return words, nil
+	}
+	current.Text += rest
+	syllables = append(syllables, current)
+
+	var b strings.Builder
+	for _, s := range syllables {
+		b.WriteString(s.Text)
+	}
+	return strings.TrimSpace(b.String()), syllables
+}
+
+// shiftSyllables returns a copy of the syllables moved by delta ms.
+func shiftSyllables(syllables []Syllable, delta int) []Syllable {
+	if syllables == nil || delta == 0 {
+		return syllables
+	}
+	result := make([]Syllable, len(syllables))
+	for i, s := range syllables {
+		result[i] = Syllable{Time: s.Time + delta, Text: s.Text}
 	}
+	return result
 }
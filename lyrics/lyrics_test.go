@@ -17,9 +17,20 @@ func TestIsTimestampLine(t *testing.T) {
 		{"valid 3-digit", "[01:02.345] lyrics", true},
 		{"valid without space", "[01:02.34]lyrics", true},
 		{"valid empty", "[01:02.34]", true},
+		{"valid without ms", "[01:02]", true},
+		{"valid 1-digit minutes", "[1:02.34] lyrics", true},
+		{"valid 3-digit minutes", "[100:02.00] lyrics", true},
+		{"valid hours", "[01:02:03.00] lyrics", true},
+		{"valid colon fraction", "[01:02:34] lyrics", true},
+		{"valid hours and colon fraction", "[01:02:03:45] lyrics", true},
 
 		// negative
-		{"missing ms", "[01:02]", false},
+		{"empty ms", "[01:02.] lyrics", false},
+		{"too long ms", "[01:02.3456] lyrics", false},
+		{"seconds overflow", "[01:60.00] lyrics", false},
+		{"minutes overflow with hours", "[01:60:03.00] lyrics", false},
+		{"too many parts", "[01:02:03:04.00] lyrics", false},
+		{"signed", "[-01:02.00] lyrics", false},
 		{"missing leading bracket", "01:02.34] lyrics", false},
 		{"missing closing bracket", "[01:02.34 lyrics", false},
 
@@ -80,6 +91,60 @@ func TestParseLrcLine(t *testing.T) {
 			input:    "[99:00.00] lyrics",
 			expected: Line{Time: 5940000, Words: "lyrics"},
 		},
+		{
+			name:     "over 99 minutes",
+			input:    "[100:02.00] lyrics",
+			expected: Line{Time: 6002000, Words: "lyrics"},
+		},
+		{
+			name:     "1-digit minutes",
+			input:    "[1:02.00] lyrics",
+			expected: Line{Time: 62000, Words: "lyrics"},
+		},
+		{
+			name:     "hours",
+			input:    "[01:02:03.50] lyrics",
+			expected: Line{Time: 3723500, Words: "lyrics"},
+		},
+		{
+			name:     "without ms",
+			input:    "[01:02] lyrics",
+			expected: Line{Time: 62000, Words: "lyrics"},
+		},
+		{
+			name:     "colon fraction",
+			input:    "[01:02:34] lyrics",
+			expected: Line{Time: 62340, Words: "lyrics"},
+		},
+		{
+			name:     "hours and colon fraction",
+			input:    "[01:02:03:45] lyrics",
+			expected: Line{Time: 3723450, Words: "lyrics"},
+		},
+		{
+			name:  "enhanced",
+			input: "[00:01.00]<00:01.00>Some <00:01.50>lyrics <00:02.00>here",
+			expected: Line{Time: 1000, Words: "Some lyrics here", Syllables: []Syllable{
+				{Time: 1000, Text: "Some "},
+				{Time: 1500, Text: "lyrics "},
+				{Time: 2000, Text: "here"},
+			}},
+		},
+		{
+			name:  "enhanced with leading text and end tag",
+			input: "[00:01.00] Some <00:01.50>ly<00:01.75>rics <00:02.00>",
+			expected: Line{Time: 1000, Words: "Some lyrics", Syllables: []Syllable{
+				{Time: 1000, Text: "Some "},
+				{Time: 1500, Text: "ly"},
+				{Time: 1750, Text: "rics "},
+				{Time: 2000, Text: ""},
+			}},
+		},
+		{
+			name:     "angle brackets without timestamps",
+			input:    "[00:01.00]<3 lyrics>",
+			expected: Line{Time: 1000, Words: "<3 lyrics>"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +156,113 @@ func TestParseLrcLine(t *testing.T) {
 		})
 	}
 }
+
+func TestInstrumental(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []Line
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"single empty line", []Line{{}}, true},
+		{"empty lines", []Line{{Time: 0}, {Time: 1000}}, true},
+		{"lyrics", []Line{{Time: 0}, {Time: 1000, Words: "lyrics"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Instrumental(tt.input); result != tt.expected {
+				t.Errorf("Instrumental(%+v) = %v; want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLineProgress(t *testing.T) {
+	line := ParseLrcLine("[00:01.00]<00:01.00>Some <00:01.50>lyrics <00:02.00>here")
+
+	tests := []struct {
+		position int
+		expected int
+	}{
+		{0, 0},
+		{1000, 1},
+		{1499, 1},
+		{1500, 2},
+		{5000, 3},
+	}
+
+	for _, tt := range tests {
+		if result := line.Progress(tt.position); result != tt.expected {
+			t.Errorf("Progress(%d) = %d; want %d", tt.position, result, tt.expected)
+		}
+	}
+}
+
+func TestParseLrcLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Line
+	}{
+		{
+			name:     "single",
+			input:    "[00:01.00] lyrics",
+			expected: []Line{{Time: 1000, Words: "lyrics"}},
+		},
+		{
+			name:  "multiple",
+			input: "[00:12.00][01:40.50]Chorus",
+			expected: []Line{
+				{Time: 12000, Words: "Chorus"},
+				{Time: 100500, Words: "Chorus"},
+			},
+		},
+		{
+			name:  "multiple enhanced",
+			input: "[00:01.00][00:11.00]<00:01.00>Cho<00:01.50>rus",
+			expected: []Line{
+				{Time: 1000, Words: "Chorus", Syllables: []Syllable{
+					{Time: 1000, Text: "Cho"}, {Time: 1500, Text: "rus"},
+				}},
+				{Time: 11000, Words: "Chorus", Syllables: []Syllable{
+					{Time: 11000, Text: "Cho"}, {Time: 11500, Text: "rus"},
+				}},
+			},
+		},
+		{
+			name:     "tag in words",
+			input:    "[00:01.00][ti: Title]",
+			expected: []Line{{Time: 1000, Words: "[ti: Title]"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseLrcLines(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseLrcLines(%q) = %+v; want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortLines(t *testing.T) {
+	lines := []Line{
+		{Time: 3000, Words: "c"},
+		{Time: 1000, Words: "a"},
+		{Time: 3000, Words: "d"},
+		{Time: 2000, Words: "b"},
+	}
+	SortLines(lines)
+
+	expected := []Line{
+		{Time: 1000, Words: "a"},
+		{Time: 2000, Words: "b"},
+		{Time: 3000, Words: "c"},
+		{Time: 3000, Words: "d"},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("SortLines() = %+v; want %+v", lines, expected)
+	}
+}
@@ -0,0 +1,171 @@
+package lyrics
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ParseSRT parses SubRip (.srt) subtitles into lines.
+func ParseSRT(r io.Reader) ([]Line, error) {
+	return parseCues(r)
+}
+
+// ParseVTT parses WebVTT (.vtt) subtitles into lines.
+func ParseVTT(r io.Reader) ([]Line, error) {
+	return parseCues(r)
+}
+
+type cue struct {
+	start, end int
+	text       string
+}
+
+// parseCues parses blocks of "start --> end" followed by text, which is
+// common for SRT and WebVTT. Blocks without timing (WEBVTT header, NOTE,
+// STYLE, REGION) are skipped. An empty line is inserted at the end of
+// a cue if the next one doesn't start right after it.
+func parseCues(r io.Reader) ([]Line, error) {
+	var (
+		cues  []cue
+		block []string
+	)
+	flush := func() {
+		if c, ok := parseCue(block); ok {
+			cues = append(cues, c)
+		}
+		block = block[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	lines := make([]timedLine, len(cues))
+	for i, c := range cues {
+		words, syllables := parseSyllables(c.start, c.text)
+		lines[i] = timedLine{
+			Line: Line{
+				Time:      c.start,
+				Words:     words,
+				Syllables: syllables,
+			},
+			End: c.end,
+		}
+	}
+	return withGaps(lines), nil
+}
+
+// timedLine is a line that has an end time.
+type timedLine struct {
+	Line
+	End int
+}
+
+// withGaps sorts the lines and inserts an empty line at the end
+// of each one that isn't immediately followed by the next one.
+func withGaps(lines []timedLine) []Line {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time < lines[j].Time
+	})
+
+	result := make([]Line, 0, len(lines)*2)
+	for i, l := range lines {
+		result = append(result, l.Line)
+		if i+1 < len(lines) && lines[i+1].Time <= l.End {
+			continue
+		}
+		if l.End > l.Time {
+			result = append(result, Line{Time: l.End})
+		}
+	}
+	return result
+}
+
+// parseCue parses a block like this:
+//
+//	1
+//	00:00:01,000 --> 00:00:04,000
+//	Text
+func parseCue(block []string) (cue, bool) {
+	for i, line := range block {
+		start, end, ok := strings.Cut(line, "-->")
+		if !ok {
+			continue
+		}
+		// WebVTT cue settings go after the end time
+		if fields := strings.Fields(end); len(fields) != 0 {
+			end = fields[0]
+		}
+
+		var c cue
+		if c.start, ok = parseCueTimestamp(start); !ok {
+			return cue{}, false
+		}
+		if c.end, ok = parseCueTimestamp(end); !ok {
+			return cue{}, false
+		}
+
+		text := make([]string, 0, len(block)-i-1)
+		for _, t := range block[i+1:] {
+			if t = cleanCueText(t); t != "" {
+				text = append(text, t)
+			}
+		}
+		c.text = strings.Join(text, " ")
+		return c, true
+	}
+	return cue{}, false
+}
+
+// parseCueTimestamp parses "00:00:01,000" (SRT) or "00:01.000" (WebVTT).
+func parseCueTimestamp(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.Replace(s, ",", ".", 1)
+	return parseTimestamp(s)
+}
+
+// cleanCueText removes formatting tags like <i> or {\an8}, keeping
+// inline timestamps, and decodes HTML entities.
+func cleanCueText(s string) string {
+	var b strings.Builder
+	for s != "" {
+		open := strings.IndexAny(s, "<{")
+		if open == -1 {
+			b.WriteString(s)
+			break
+		}
+		closeChar := byte('>')
+		if s[open] == '{' {
+			closeChar = '}'
+		}
+		close := strings.IndexByte(s[open:], closeChar)
+		if close == -1 {
+			b.WriteString(s)
+			break
+		}
+		close += open
+
+		b.WriteString(s[:open])
+		if _, ok := parseTimestamp(s[open+1 : close]); ok && closeChar == '>' {
+			b.WriteString(s[open : close+1])
+		}
+		s = s[close+1:]
+	}
+	return strings.TrimSpace(html.UnescapeString(b.String()))
+}
@@ -0,0 +1,71 @@
+package lyrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSRT(t *testing.T) {
+	const input = "1\r\n" +
+		"00:00:01,000 --> 00:00:04,000\r\n" +
+		"<i>First</i> line\r\n" +
+		"continued\r\n" +
+		"\r\n" +
+		"2\r\n" +
+		"00:00:04,000 --> 00:00:06,500\r\n" +
+		"{\\an8}Second &amp; line\r\n" +
+		"\r\n" +
+		"3\r\n" +
+		"00:01:10,000 --> 00:01:12,000\r\n" +
+		"Third line\r\n"
+
+	lines, err := ParseSRT(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Line{
+		{Time: 1000, Words: "First line continued"},
+		{Time: 4000, Words: "Second & line"},
+		{Time: 6500, Words: ""},
+		{Time: 70000, Words: "Third line"},
+		{Time: 72000, Words: ""},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("ParseSRT() = %+v; want %+v", lines, expected)
+	}
+}
+
+func TestParseVTT(t *testing.T) {
+	const input = "WEBVTT - lyrics\n" +
+		"\n" +
+		"NOTE exported from somewhere\n" +
+		"\n" +
+		"STYLE\n" +
+		"::cue { color: white }\n" +
+		"\n" +
+		"00:05.000 --> 00:07.000 align:start\n" +
+		"<c.lyrics>Second</c> line\n" +
+		"\n" +
+		"intro\n" +
+		"00:00:01.000 --> 00:00:05.000\n" +
+		"<00:00:01.000>First <00:00:02.500>line\n"
+
+	lines, err := ParseVTT(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Line{
+		{Time: 1000, Words: "First line", Syllables: []Syllable{
+			{Time: 1000, Text: "First "},
+			{Time: 2500, Text: "line"},
+		}},
+		{Time: 5000, Words: "Second line"},
+		{Time: 7000, Words: ""},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("ParseVTT() = %+v; want %+v", lines, expected)
+	}
+}
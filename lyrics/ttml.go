@@ -0,0 +1,258 @@
+package lyrics
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseTTML parses TTML lyrics (as used by Apple Music) into lines.
+// Timed <span>s become syllables and ttm:agent becomes the singer.
+func ParseTTML(r io.Reader) ([]Line, error) {
+	var (
+		decoder = xml.NewDecoder(r)
+
+		// agent id -> name
+		agents  = map[string]string{}
+		agentID string
+		inName  bool
+
+		lines []timedLine
+		p     *ttmlParagraph
+		// whether each open <span> is a timed one
+		spans []bool
+		span  *Syllable
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "agent":
+				agentID = attr(t, "id")
+				if agentID != "" {
+					agents[agentID] = agentID
+				}
+			case "name":
+				inName = agentID != ""
+			case "p":
+				p = &ttmlParagraph{agent: attr(t, "agent")}
+				p.begin, _ = parseTTMLTime(attr(t, "begin"))
+				p.end, _ = parseTTMLTime(attr(t, "end"))
+			case "span":
+				if p == nil {
+					break
+				}
+				begin, ok := parseTTMLTime(attr(t, "begin"))
+				if ok && span == nil {
+					span = &Syllable{Time: begin}
+				}
+				spans = append(spans, ok)
+			case "br":
+				if p != nil {
+					p.appendText(" ", span)
+				}
+			}
+
+		case xml.CharData:
+			if inName {
+				if name := strings.TrimSpace(string(t)); name != "" {
+					agents[agentID] = name
+				}
+			} else if p != nil {
+				p.appendText(collapseSpace(string(t)), span)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "agent":
+				agentID = ""
+			case "name":
+				inName = false
+			case "span":
+				if len(spans) == 0 {
+					break
+				}
+				timed := spans[len(spans)-1]
+				spans = spans[:len(spans)-1]
+				if timed && span != nil && !slices.Contains(spans, true) {
+					p.syllables = append(p.syllables, *span)
+					span = nil
+				}
+			case "p":
+				if p != nil {
+					lines = append(lines, p.line(agents))
+				}
+				p, spans, span = nil, nil, nil
+			}
+		}
+	}
+
+	return withGaps(lines), nil
+}
+
+type ttmlParagraph struct {
+	begin, end int
+	agent      string
+
+	text      string
+	syllables []Syllable
+}
+
+// appendText adds the text to the current span, the last syllable
+// or the paragraph itself, whatever is available.
+func (p *ttmlParagraph) appendText(s string, span *Syllable) {
+	switch {
+	case span != nil:
+		span.Text = joinText(span.Text, s)
+	case len(p.syllables) != 0:
+		last := &p.syllables[len(p.syllables)-1]
+		last.Text = joinText(last.Text, s)
+	default:
+		p.text = joinText(p.text, s)
+	}
+}
+
+func (p *ttmlParagraph) line(agents map[string]string) timedLine {
+	line := Line{
+		Time:   p.begin,
+		Words:  strings.TrimSpace(p.text),
+		Singer: agents[p.agent],
+	}
+	if line.Singer == "" {
+		line.Singer = p.agent
+	}
+
+	if len(p.syllables) != 0 {
+		syllables := p.syllables
+		syllables[0].Text = strings.TrimLeftFunc(syllables[0].Text, unicode.IsSpace)
+		last := len(syllables) - 1
+		syllables[last].Text = strings.TrimRightFunc(syllables[last].Text, unicode.IsSpace)
+
+		var b strings.Builder
+		for _, s := range syllables {
+			b.WriteString(s.Text)
+		}
+		line.Words = b.String()
+		line.Syllables = syllables
+	}
+	return timedLine{Line: line, End: p.end}
+}
+
+// ttmlFrameRate is the default ttp:frameRate.
+const ttmlFrameRate = 30
+
+// parseTTMLTime parses clock time ("1:10.500", "00:01:10.500"),
+// seconds ("70.5") or offset time ("70.5s", "500ms") into ms.
+func parseTTMLTime(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if strings.Contains(s, ":") {
+		return parseClockTime(s)
+	}
+
+	multiplier := 1000.0
+	for _, unit := range []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"ms", 1},
+		{"s", 1000},
+		{"m", 60 * 1000},
+		{"h", 60 * 60 * 1000},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return int(math.Round(f * multiplier)), true
+}
+
+// parseClockTime parses "hh:mm:ss[.fraction]" or "hh:mm:ss:frames"
+// into ms. Apple Music also uses "m:ss.fraction" without the hours.
+func parseClockTime(s string) (int, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 4 {
+		return 0, false
+	}
+
+	var frames float64
+	if len(parts) == 4 {
+		f, err := strconv.ParseFloat(parts[3], 64)
+		if err != nil || f < 0 || strings.Contains(parts[2], ".") {
+			return 0, false
+		}
+		frames = f
+		parts = parts[:3]
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	var total int
+	for _, p := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	ms := float64(total*60)*1000 + seconds*1000 + frames*1000/ttmlFrameRate
+	return int(math.Round(ms)), true
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// collapseSpace replaces runs of whitespace with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		b.WriteRune(r)
+		space = false
+	}
+	return b.String()
+}
+
+// joinText appends s to text without doubling spaces.
+func joinText(text, s string) string {
+	if strings.HasSuffix(text, " ") {
+		s = strings.TrimPrefix(s, " ")
+	}
+	return text + s
+}
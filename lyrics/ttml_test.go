@@ -0,0 +1,83 @@
+package lyrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTTML(t *testing.T) {
+	const input = `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttm="http://www.w3.org/ns/ttml#metadata" xmlns:itunes="http://music.apple.com/lyric-ttml-internal" itunes:timing="Word" xml:lang="en">
+  <head>
+    <metadata>
+      <ttm:agent type="person" xml:id="v1">
+        <ttm:name type="full">Lead Singer</ttm:name>
+      </ttm:agent>
+      <ttm:agent type="person" xml:id="v2"/>
+    </metadata>
+  </head>
+  <body dur="1:00.000">
+    <div begin="1.000" end="8.000">
+      <p begin="1.000" end="3.000" ttm:agent="v1">
+        <span begin="1.000" end="1.500">Hel</span><span begin="1.500" end="2.000">lo</span>
+        <span begin="2.000" end="3.000">world</span>
+      </p>
+      <p begin="0:03.000" end="0:05.000" ttm:agent="v2"><span begin="3s" end="4s">Second</span> <span ttm:role="x-bg"><span begin="4000ms" end="5000ms">(line)</span></span></p>
+      <p begin="00:00:06.500" end="00:00:08.000">Untimed &amp; plain</p>
+    </div>
+  </body>
+</tt>`
+
+	lines, err := ParseTTML(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Line{
+		{Time: 1000, Words: "Hello world", Singer: "Lead Singer", Syllables: []Syllable{
+			{Time: 1000, Text: "Hel"},
+			{Time: 1500, Text: "lo "},
+			{Time: 2000, Text: "world"},
+		}},
+		{Time: 3000, Words: "Second (line)", Singer: "v2", Syllables: []Syllable{
+			{Time: 3000, Text: "Second "},
+			{Time: 4000, Text: "(line)"},
+		}},
+		{Time: 5000, Words: ""},
+		{Time: 6500, Words: "Untimed & plain"},
+		{Time: 8000, Words: ""},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("ParseTTML() = %+v; want %+v", lines, expected)
+	}
+}
+
+func TestParseTTMLTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		ok       bool
+	}{
+		{"1:10.500", 70500, true},
+		{"00:01:10", 70000, true},
+		{"00:01:10.5", 70500, true},
+		{"01:00:00.000", 3600000, true},
+		{"00:01:10:15", 70500, true},
+		{"70.5", 70500, true},
+		{"70.5s", 70500, true},
+		{"500ms", 500, true},
+		{"1.5m", 90000, true},
+		{"", 0, false},
+		{"1:2:3:4:5", 0, false},
+		{"00:01:10.5:15", 0, false},
+		{"00:xx:10", 0, false},
+	}
+
+	for _, tt := range tests {
+		result, ok := parseTTMLTime(tt.input)
+		if result != tt.expected || ok != tt.ok {
+			t.Errorf("parseTTMLTime(%q) = %d, %v; want %d, %v", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
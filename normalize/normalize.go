@@ -0,0 +1,95 @@
+// Package normalize prepares artist and track names for matching.
+package normalize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// versionPhrase is "Live", "Live at X", "Remastered 2011", "2011 Remaster"...
+const versionPhrase = `(?:live(?:\s+(?:at|from|in|on)\s[^)\]]*|\s+version)?` +
+	`|(?:\d{4}\s+)?remaster(?:ed)?(?:\s+\d{4})?(?:\s+version)?)`
+
+var (
+	// (feat. X), [ft X], (featuring X), (with X) but not (With Love)
+	featBrackets = regexp.MustCompile(`[(\[]\s*(?:(?i:feat\.?|ft\.?|featuring)|with)\s+[^)\]\s][^)\]]*[)\]]`)
+	// feat. X, ft. X but not Little Feat
+	feat = regexp.MustCompile(`\s(?i:feat|ft)\.\s*\S.*$`)
+	// (Live), [2011 Remaster]
+	versionBrackets = regexp.MustCompile(`[(\[]\s*` + versionPhrase + `\s*[)\]]`)
+	// - Remastered 2011, - Live at Wembley but not - Live Forever
+	versionSuffix = regexp.MustCompile(`\s-\s+` + versionPhrase + `\s*$`)
+)
+
+// Fold lowercases the string, replaces compatibility characters
+// (like full-width letters) with the common ones and removes diacritics.
+func Fold(s string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		result = s
+	}
+	return strings.ToLower(result)
+}
+
+// String folds the name, removes featured artists and version suffixes,
+// replaces "&" with "and" and strips punctuation.
+func String(s string) string {
+	// before folding, since only the lowercase "with" marks an artist
+	s = featBrackets.ReplaceAllString(s, " ")
+	s = feat.ReplaceAllString(s, "")
+	s = Fold(s)
+	s = versionBrackets.ReplaceAllString(s, " ")
+	s = versionSuffix.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "&", " and ")
+	return StripPunct(s)
+}
+
+// StripPunct removes apostrophes, replaces the other punctuation
+// and symbols with spaces and collapses the spaces.
+func StripPunct(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Words returns the words of the normalized name, or nil if there are none.
+func Words(s string) []string {
+	words := strings.Fields(String(s))
+	if len(words) == 0 {
+		return nil
+	}
+	return words
+}
+
+// Similarity returns the Sørensen–Dice coefficient of the words.
+func Similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var common int
+	used := make([]bool, len(b))
+	for _, w := range a {
+		for i, bw := range b {
+			if !used[i] && bw == w {
+				used[i] = true
+				common++
+				break
+			}
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
@@ -0,0 +1,105 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Kerosene", "kerosene"},
+		{"  Crystal   Castles ", "crystal castles"},
+		{"Beyoncé", "beyonce"},
+		{"Sigur Rós", "sigur ros"},
+		{"ＫＥＲＯＳＥＮＥ", "kerosene"},
+		{"Don't Stop Me Now", "dont stop me now"},
+		{"Don’t Stop Me Now", "dont stop me now"},
+		{"Simon & Garfunkel", "simon and garfunkel"},
+		{"AC/DC", "ac dc"},
+		{"Stay (feat. Justin Bieber)", "stay"},
+		{"Stay [ft. Justin Bieber]", "stay"},
+		{"Peaches (with Daniel Caesar)", "peaches"},
+		{"The Kid LAROI feat. Justin Bieber", "the kid laroi"},
+		{"The Kid LAROI ft. Justin Bieber", "the kid laroi"},
+		{"Something - Remastered 2009", "something"},
+		{"Something - 2009 Remaster", "something"},
+		{"Bohemian Rhapsody (Live)", "bohemian rhapsody"},
+		{"Bohemian Rhapsody - Live at Wembley '86", "bohemian rhapsody"},
+		{"Heroes [2017 Remaster]", "heroes"},
+		// only suffixes are removed
+		{"Live and Let Die", "live and let die"},
+		{"Live Forever - Remastered", "live forever"},
+		{"Oasis - Live Forever", "oasis live forever"},
+		{"Live Forever (Live at Knebworth)", "live forever"},
+		{"Little Feat", "little feat"},
+		{"The Feat Band", "the feat band"},
+		{"Ft Worth Blues", "ft worth blues"},
+		{"Sent (With Love)", "sent with love"},
+		{"Stay (Featuring Justin Bieber)", "stay"},
+		{"Left Alone", "left alone"},
+		{"Twenty-One Pilots", "twenty one pilots"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if result := String(tt.input); result != tt.expected {
+			t.Errorf("String(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"Beyoncé & Jay-Z", []string{"beyonce", "and", "jay", "z"}},
+		{"Intro (Live)", []string{"intro"}},
+		{" - ", nil},
+	}
+
+	for _, tt := range tests {
+		if result := Words(tt.input); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Words(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestStripPunct(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"don't stop me now", "dont stop me now"},
+		{"jay-z", "jay z"},
+		{"ac/dc  (live)", "ac dc live"},
+		{"simon & garfunkel", "simon garfunkel"},
+	}
+
+	for _, tt := range tests {
+		if result := StripPunct(tt.input); result != tt.expected {
+			t.Errorf("StripPunct(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     []string
+		expected float64
+	}{
+		{[]string{"kerosene"}, []string{"kerosene"}, 1},
+		{[]string{"no", "love"}, []string{"love"}, 2.0 / 3},
+		{[]string{"love", "love"}, []string{"love"}, 2.0 / 3},
+		{nil, []string{"love"}, 0},
+	}
+
+	for _, tt := range tests {
+		if result := Similarity(tt.a, tt.b); result != tt.expected {
+			t.Errorf("Similarity(%q, %q) = %v; want %v", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package player
 
+import "context"
+
+// Player returns the state of the player, or nil if nothing is playing.
+// It should give up when the context is done.
 type Player interface {
-	State() (*State, error)
+	State(ctx context.Context) (*State, error)
 }
 
 type State struct {
@@ -11,8 +15,15 @@ type State struct {
 	Artist string
 	// Track is the name of the track.
 	Track string
+	// Album is the name of the album, if known.
+	Album string
+	// Duration of the current track in ms, if known.
+	Duration int
 	// Position of the current track in ms.
 	Position int
 	// Playing means whether the track is playing at the moment.
 	Playing bool
+	// File is the path or URI of the file being played, if known.
+	// Relative paths are relative to the music directory.
+	File string
 }
@@ -1,59 +1,107 @@
 package pool
 
 import (
+	"context"
+	"errors"
 	"github.com/raitonoberu/sptlrx/config"
 	"github.com/raitonoberu/sptlrx/lyrics"
 	"github.com/raitonoberu/sptlrx/player"
+	"io"
 	"time"
 )
 
 // Update represents the state of the lyrics.
 type Update struct {
+	// Lyrics of the current track, nil if there are none.
+	Lyrics *lyrics.Lyrics
+	// Lines are the lines of the lyrics.
 	Lines   []lyrics.Line
 	Index   int
 	Playing bool
+	// Progress is the number of syllables of the current line
+	// that have been sung, if the line has word-level timing.
+	Progress int
+	// Instrumental means that the track has no lyrics.
+	Instrumental bool
+	// Loading means that the lyrics of the track are being looked for.
+	Loading bool
 
 	Err error
 }
 
-// Listen polls for lyrics updates and writes them to the channel.
+// Listen polls for lyrics updates and writes them to the channel until
+// the context is done. Then it closes the channel and the player, if it
+// implements io.Closer (e.g. runs a server).
 func Listen(
+	ctx context.Context,
 	player player.Player,
 	provider lyrics.Provider,
 	conf *config.Config,
 	ch chan Update,
 ) {
+	defer close(ch)
+
 	stateCh := make(chan playerState)
-	go listenPlayer(player, stateCh, conf.UpdateInterval)
+	playerDone := make(chan struct{})
+	go func() {
+		listenPlayer(ctx, player, stateCh, conf.UpdateInterval, conf.Timeout)
+		close(playerDone)
+	}()
+	defer func() {
+		// the player may still be in use until then
+		<-playerDone
+		if closer, ok := player.(io.Closer); ok {
+			closer.Close()
+		}
+	}()
+
+	resultCh := make(chan fetchResult)
 
 	ticker := time.NewTicker(
 		time.Millisecond * time.Duration(conf.TimerInterval),
 	)
+	defer ticker.Stop()
 
 	var (
 		state      playerState
 		index      int
+		progress   int
+		result     *lyrics.Lyrics
 		lines      []lyrics.Line
+		loading    bool
+		fetchID    int
+		fetchErr   error
+		cancel     = func() {}
 		lastUpdate time.Time
 	)
+	defer func() { cancel() }()
 
 	for {
 		changed := false
 
 		select {
+		case <-ctx.Done():
+			return
 		case newState := <-stateCh:
 			lastUpdate = time.Now()
 
 			if newState.ID != state.ID {
 				changed = true
-				if newState.ID != "" {
-					newLines, err := provider.Lyrics(newState.Artist, newState.Track)
-					if err != nil {
-						state.Err = err
-					}
-					lines = newLines
-				} else {
-					lines = nil
+				// stop looking for the lyrics of the previous track
+				cancel()
+				result, lines, fetchErr = nil, nil, nil
+				loading = newState.ID != ""
+				if loading {
+					fetchCtx, cancelFetch := withTimeout(ctx, conf.Timeout)
+					cancel = cancelFetch
+					fetchID++
+					go fetch(fetchCtx, provider, fetchID, lyrics.Query{
+						Artist:   newState.Artist,
+						Track:    newState.Track,
+						Album:    newState.Album,
+						Duration: newState.Duration,
+						File:     newState.File,
+					}, resultCh, ctx.Done())
 				}
 				index = 0
 			}
@@ -61,8 +109,24 @@ func Listen(
 				changed = true
 			}
 			state = newState
+		case r := <-resultCh:
+			if r.id != fetchID {
+				// the track has changed since
+				break
+			}
+			changed = true
+			cancel()
+			loading = false
+			// cancelled because the track has changed
+			if r.err != nil && !errors.Is(r.err, context.Canceled) {
+				fetchErr = r.err
+			}
+			if r.lyrics != nil {
+				result, lines = r.lyrics, r.lyrics.Lines
+			}
+			index = 0
 		case <-ticker.C:
-			if !state.Playing || !lyrics.Timesynced(lines) {
+			if !state.Playing || result == nil || !result.Synced {
 				break
 			}
 
@@ -77,12 +141,34 @@ func Listen(
 			index = newIndex
 		}
 
+		var newProgress int
+		if len(lines) != 0 {
+			newProgress = lines[index].Progress(state.Position)
+		}
+		if newProgress != progress {
+			changed = true
+			progress = newProgress
+		}
+
 		if changed {
-			ch <- Update{
-				Lines:   lines,
-				Index:   index,
-				Playing: state.Playing,
-				Err:     state.Err,
+			err := state.Err
+			if err == nil {
+				err = fetchErr
+			}
+			update := Update{
+				Lyrics:       result,
+				Lines:        lines,
+				Index:        index,
+				Playing:      state.Playing,
+				Progress:     progress,
+				Instrumental: lyrics.Instrumental(lines),
+				Loading:      loading,
+				Err:          err,
+			}
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}
@@ -93,17 +179,61 @@ type playerState struct {
 	Err error
 }
 
-func listenPlayer(player player.Player, ch chan playerState, interval int) {
+func listenPlayer(ctx context.Context, player player.Player, ch chan playerState, interval int, timeout time.Duration) {
 	for {
-		state, err := player.State()
+		stateCtx, cancel := withTimeout(ctx, timeout)
+		state, err := player.State(stateCtx)
+		cancel()
 
 		st := playerState{Err: err}
 		if state != nil {
 			st.State = *state
 		}
-		ch <- st
+		select {
+		case ch <- st:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-time.After(time.Millisecond * time.Duration(interval)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
-		time.Sleep(time.Millisecond * time.Duration(interval))
+// withTimeout is like context.WithTimeout,
+// but a non-positive timeout means no timeout.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// fetchResult is the result of looking for the lyrics of the track.
+type fetchResult struct {
+	// id tells the results for the same track played again apart.
+	id     int
+	lyrics *lyrics.Lyrics
+	err    error
+}
+
+// fetch looks for the lyrics in the background, so that the position
+// keeps updating meanwhile. The result is dropped once done is closed.
+func fetch(
+	ctx context.Context,
+	provider lyrics.Provider,
+	id int,
+	query lyrics.Query,
+	ch chan fetchResult,
+	done <-chan struct{},
+) {
+	result, err := provider.Lyrics(ctx, query)
+	select {
+	case ch <- fetchResult{id: id, lyrics: result, err: err}:
+	case <-done:
 	}
 }
 
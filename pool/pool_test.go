@@ -1,18 +1,31 @@
 package pool
 
 import (
+	"context"
 	"math"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/raitonoberu/sptlrx/config"
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/player"
 	"github.com/raitonoberu/sptlrx/services/lrclib"
 )
 
 func TestGetIndex(t *testing.T) {
-	service := lrclib.New()
-	lines, err := service.Lyrics("Death Grips", "No Love")
+	service := lrclib.New(lrclib.DefaultAddress, "")
+	result, err := service.Lyrics(context.Background(), lyrics.Query{
+		Artist: "Death Grips",
+		Track:  "No Love",
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if result == nil {
+		t.Fatal("lyrics not found")
+	}
+	lines := result.Lines
 
 	test := func(pos, curIndex, expected int) {
 		if index := getIndex(pos, curIndex, lines); index != expected {
@@ -36,3 +49,159 @@ func TestGetIndex(t *testing.T) {
 	test(lines[0].Time-1, 0, 0)        // 0 if pos < first.Time
 	test(math.MaxInt, 0, len(lines)-1) // last if pos > last.Time
 }
+
+type fakePlayer struct {
+	mu     sync.Mutex
+	state  player.State
+	closed bool
+}
+
+func (p *fakePlayer) State(context.Context) (*player.State, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.state
+	return &state, nil
+}
+
+func (p *fakePlayer) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *fakePlayer) play(id string) {
+	p.mu.Lock()
+	p.state = player.State{ID: id, Track: id, Playing: true}
+	p.mu.Unlock()
+}
+
+// slowProvider only finds lyrics for "fast" and
+// waits for the context to be done for the rest.
+type slowProvider struct {
+	cancelled chan string
+}
+
+func (p *slowProvider) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	if query.Track == "fast" {
+		return &lyrics.Lyrics{
+			Lines:  []lyrics.Line{{Time: 0, Words: "fast"}, {Time: 1000000}},
+			Synced: true,
+		}, nil
+	}
+	<-ctx.Done()
+	p.cancelled <- query.Track
+	return nil, ctx.Err()
+}
+
+func TestListen(t *testing.T) {
+	conf := config.New()
+	conf.UpdateInterval = 10
+	conf.TimerInterval = 10
+
+	p := &fakePlayer{}
+	p.play("slow")
+	provider := &slowProvider{cancelled: make(chan string, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan Update)
+	go Listen(ctx, p, provider, conf, ch)
+
+	next := func() Update {
+		t.Helper()
+		select {
+		case update := <-ch:
+			return update
+		case <-time.After(time.Second):
+			t.Fatal("no update")
+			return Update{}
+		}
+	}
+
+	if update := next(); !update.Loading || update.Lyrics != nil {
+		t.Fatalf("Update = %+v; want loading", update)
+	}
+
+	p.play("fast")
+	select {
+	case track := <-provider.cancelled:
+		if track != "slow" {
+			t.Errorf("cancelled %q; want %q", track, "slow")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup of the previous track was not cancelled")
+	}
+
+	for {
+		update := next()
+		if update.Err != nil {
+			t.Fatalf("Update.Err = %v; want nil", update.Err)
+		}
+		if update.Loading {
+			continue
+		}
+		if update.Lyrics == nil || update.Lines[0].Words != "fast" {
+			t.Fatalf("Update = %+v; want lyrics of the current track", update)
+		}
+		break
+	}
+}
+
+func TestListenCancel(t *testing.T) {
+	conf := config.New()
+	conf.UpdateInterval = 10
+	conf.TimerInterval = 10
+
+	p := &fakePlayer{}
+	p.play("slow")
+	provider := &slowProvider{cancelled: make(chan string, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan Update)
+	go Listen(ctx, p, provider, conf, ch)
+
+	<-ch
+	cancel()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if ok {
+				continue
+			}
+		case <-timeout:
+			t.Fatal("channel was not closed")
+		}
+		break
+	}
+
+	select {
+	case <-provider.cancelled:
+	case <-time.After(time.Second):
+		t.Error("lookup was not cancelled")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		t.Error("player was not closed")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		ctx, cancel := withTimeout(context.Background(), timeout)
+		if _, ok := ctx.Deadline(); ok || ctx.Err() != nil {
+			t.Errorf("withTimeout(%v) has a deadline; want none", timeout)
+		}
+		cancel()
+	}
+
+	ctx, cancel := withTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Errorf("withTimeout(%v) has no deadline", time.Second)
+	}
+}
@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"github.com/raitonoberu/sptlrx/player"
 	"io"
@@ -35,11 +36,17 @@ type Client struct {
 	position int
 	title    string
 	artist   string
+	album    string
+	duration int
 
 	updateTime time.Time
 
 	stateMu sync.Mutex
 	connMu  sync.Mutex
+
+	server *http.Server
+	// cancel disconnects the browser
+	cancel context.CancelFunc
 }
 
 func (c *Client) handler(w http.ResponseWriter, r *http.Request) {
@@ -111,6 +118,15 @@ func (c *Client) processMessage(msg string) {
 		c.stateMu.Lock()
 		c.artist = data
 		c.stateMu.Unlock()
+	case "ALBUM":
+		c.stateMu.Lock()
+		c.album = data
+		c.stateMu.Unlock()
+	case "DURATION_SECONDS":
+		dur, _ := strconv.Atoi(data)
+		c.stateMu.Lock()
+		c.duration = dur * 1000
+		c.stateMu.Unlock()
 	case "POSITION_SECONDS":
 		pos, _ := strconv.Atoi(data)
 		c.stateMu.Lock()
@@ -126,14 +142,25 @@ func (c *Client) start(port int) error {
 		return err
 	}
 
-	server := &http.Server{
-		Handler: http.HandlerFunc(c.handler),
+	// hijacked connections are not closed with the server,
+	// so the handler stops when the base context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	c.server = &http.Server{
+		Handler:     http.HandlerFunc(c.handler),
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
-	go server.Serve(l)
+	c.cancel = cancel
+	go c.server.Serve(l)
 	return nil
 }
 
-func (c *Client) State() (*player.State, error) {
+// Close stops the server and disconnects the browser.
+func (c *Client) Close() error {
+	c.cancel()
+	return c.server.Close()
+}
+
+func (c *Client) State(ctx context.Context) (*player.State, error) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
@@ -151,6 +178,8 @@ func (c *Client) State() (*player.State, error) {
 		ID:       id,
 		Artist:   c.artist,
 		Track:    c.title,
+		Album:    c.album,
+		Duration: c.duration,
 		Position: position,
 		Playing:  c.state == playing,
 	}, nil
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/normalize"
+)
+
+// Directory is the default location of the cache.
+var Directory = filepath.Join(xdg.CacheHome, "sptlrx", "lyrics")
+
+// durationStep is the precision of the track duration in the key, in ms.
+// Players report slightly different durations for the same file.
+const durationStep = 5000
+
+// New returns a provider that caches lyrics of the given one in dir.
+// Synced lyrics are kept for ttl (0 means forever), plain lyrics and
+// not found results for missTTL, so that synced lyrics added later
+// are picked up. Expired lyrics are still used if the provider fails.
+func New(provider lyrics.Provider, dir string, ttl, missTTL time.Duration) *Client {
+	return &Client{
+		provider: provider,
+		dir:      dir,
+		ttl:      ttl,
+		missTTL:  missTTL,
+	}
+}
+
+// Client implements lyrics.Provider
+type Client struct {
+	provider lyrics.Provider
+	dir      string
+	ttl      time.Duration
+	missTTL  time.Duration
+}
+
+// Entry is a cached result of the provider.
+type Entry struct {
+	Artist string `json:"artist"`
+	Track  string `json:"track"`
+	// Duration of the track in ms, 0 if unknown.
+	Duration int            `json:"duration,omitempty"`
+	Time     time.Time      `json:"time"`
+	Lyrics   *lyrics.Lyrics `json:"lyrics"`
+	// Path of the entry file, set by List. It differs from the current
+	// key if the entry was written by an older version.
+	Path string `json:"-"`
+}
+
+// Found reports whether the provider has found lyrics.
+func (e *Entry) Found() bool {
+	return e.Lyrics != nil && len(e.Lyrics.Lines) != 0
+}
+
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	if query.Artist == "" && query.Track == "" {
+		return c.provider.Lyrics(ctx, query)
+	}
+
+	path := c.path(query)
+	entry, _ := readEntry(path)
+	if entry != nil && c.fresh(entry) {
+		return entry.Lyrics, nil
+	}
+
+	result, err := c.provider.Lyrics(ctx, query)
+	if err != nil {
+		if entry != nil {
+			// we are probably offline
+			return entry.Lyrics, nil
+		}
+		return nil, err
+	}
+
+	// failing to cache is not a reason to fail
+	writeEntry(path, &Entry{
+		Artist:   query.Artist,
+		Track:    query.Track,
+		Duration: query.Duration,
+		Time:     time.Now(),
+		Lyrics:   result,
+	})
+	return result, nil
+}
+
+func (c *Client) fresh(e *Entry) bool {
+	age := time.Since(e.Time)
+	if !e.Found() || !e.Lyrics.Synced {
+		return age < c.missTTL
+	}
+	return c.ttl == 0 || age < c.ttl
+}
+
+func (c *Client) path(query lyrics.Query) string {
+	return filepath.Join(c.dir, Key(query.Artist, query.Track, query.Duration)+".json")
+}
+
+// List returns all entries in the cache directory.
+func List(dir string) ([]*Entry, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		entry, err := readEntry(path)
+		if err != nil {
+			// skip broken entries
+			continue
+		}
+		entry.Path = path
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Get returns the latest entry for the track of any duration,
+// or nil if there is none.
+func Get(dir, artist, track string) (*Entry, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *Entry
+	for _, e := range entries {
+		if sameTrack(e, artist, track) && (latest == nil || e.Time.After(latest.Time)) {
+			latest = e
+		}
+	}
+	return latest, nil
+}
+
+// Remove removes the entries for the track of any duration.
+// It returns fs.ErrNotExist if there are none.
+func Remove(dir, artist, track string) error {
+	entries, err := List(dir)
+	if err != nil {
+		return err
+	}
+
+	var removed bool
+	for _, e := range entries {
+		if !sameTrack(e, artist, track) {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil {
+			return err
+		}
+		removed = true
+	}
+	if !removed {
+		return fs.ErrNotExist
+	}
+	return nil
+}
+
+func sameTrack(e *Entry, artist, track string) bool {
+	return foldKey(e.Artist) == foldKey(artist) && foldKey(e.Track) == foldKey(track)
+}
+
+// Prune removes entries older than the given duration
+// and returns the number of removed ones.
+func Prune(dir string, olderThan time.Duration) (int, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, e := range entries {
+		if time.Since(e.Time) < olderThan {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Key returns the name of the cache entry for the track. Only the case,
+// diacritics and spaces are ignored, so that different versions of the
+// track like "(Live)" don't share the entry. The duration (in ms) is
+// rounded to a few seconds, since it tells apart the versions too.
+func Key(artist, track string, duration int) string {
+	step := (duration + durationStep/2) / durationStep
+	normalized := foldKey(artist) + "\n" + foldKey(track) + "\n" + strconv.Itoa(step)
+	sum := sha1.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func foldKey(s string) string {
+	return strings.Join(strings.Fields(normalize.Fold(s)), " ")
+}
+
+func readEntry(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entry Entry
+	if err := json.NewDecoder(f).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func writeEntry(path string, entry *Entry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that we never read a partial one
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := json.NewEncoder(f).Encode(entry); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+type fakeProvider struct {
+	result *lyrics.Lyrics
+	err    error
+	calls  int
+}
+
+func (p *fakeProvider) Lyrics(context.Context, lyrics.Query) (*lyrics.Lyrics, error) {
+	p.calls++
+	return p.result, p.err
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	provider := &fakeProvider{result: &lyrics.Lyrics{
+		Lines:  []lyrics.Line{{Time: 0, Words: "synced"}, {Time: 1000, Words: "lyrics"}},
+		Source: "lrclib",
+		Synced: true,
+		URL:    "https://lrclib.net/api/get/1",
+	}}
+	client := New(provider, dir, time.Hour, time.Hour)
+	query := lyrics.Query{Artist: "Artist", Track: "Track"}
+
+	for i := 0; i < 2; i++ {
+		result, err := client.Lyrics(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, provider.result) {
+			t.Errorf("Lyrics() = %+v; want %+v", result, provider.result)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider was called %d times; want 1", provider.calls)
+	}
+
+	// normalized key
+	if _, err := client.Lyrics(context.Background(), lyrics.Query{Artist: " ARTIST ", Track: "track"}); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider was called %d times; want 1", provider.calls)
+	}
+}
+
+func TestCacheExpired(t *testing.T) {
+	dir := t.TempDir()
+	cached := &lyrics.Lyrics{Lines: []lyrics.Line{{Time: 1000, Words: "cached"}}, Synced: true}
+	provider := &fakeProvider{err: errors.New("offline")}
+	client := New(provider, dir, time.Hour, time.Hour)
+	query := lyrics.Query{Artist: "Artist", Track: "Track"}
+
+	err := writeEntry(client.path(query), &Entry{
+		Artist: query.Artist,
+		Track:  query.Track,
+		Time:   time.Now().Add(-2 * time.Hour),
+		Lyrics: cached,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expired entry is used if the provider fails
+	result, err := client.Lyrics(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, cached) {
+		t.Errorf("Lyrics() = %+v; want %+v", result, cached)
+	}
+
+	// and refreshed if it doesn't
+	provider.err = nil
+	provider.result = &lyrics.Lyrics{Lines: []lyrics.Line{{Time: 1000, Words: "fresh"}}}
+	result, err = client.Lyrics(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, provider.result) {
+		t.Errorf("Lyrics() = %+v; want %+v", result, provider.result)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider was called %d times; want 2", provider.calls)
+	}
+}
+
+func TestCacheMiss(t *testing.T) {
+	dir := t.TempDir()
+	provider := &fakeProvider{}
+	query := lyrics.Query{Artist: "Artist", Track: "Track"}
+
+	client := New(provider, dir, 0, time.Hour)
+	for i := 0; i < 2; i++ {
+		if result, err := client.Lyrics(context.Background(), query); err != nil || result != nil {
+			t.Errorf("Lyrics() = %+v, %v; want nil, nil", result, err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider was called %d times; want 1", provider.calls)
+	}
+
+	// misses are not remembered for long
+	client = New(provider, dir, 0, 0)
+	if _, err := client.Lyrics(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider was called %d times; want 2", provider.calls)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("cache has %d files; want 1", len(entries))
+	}
+}
+
+func TestCachePlain(t *testing.T) {
+	dir := t.TempDir()
+	provider := &fakeProvider{result: &lyrics.Lyrics{Lines: []lyrics.Line{{Words: "plain"}}}}
+	query := lyrics.Query{Artist: "Artist", Track: "Track"}
+
+	client := New(provider, dir, 0, time.Hour)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Lyrics(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider was called %d times; want 1", provider.calls)
+	}
+
+	// plain lyrics are not kept forever, synced ones may appear later
+	client = New(provider, dir, 0, 0)
+	provider.result = &lyrics.Lyrics{Lines: []lyrics.Line{{Time: 1000, Words: "synced"}}, Synced: true}
+	result, err := client.Lyrics(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, provider.result) {
+		t.Errorf("Lyrics() = %+v; want %+v", result, provider.result)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider was called %d times; want 2", provider.calls)
+	}
+}
+
+func TestGetRemove(t *testing.T) {
+	dir := t.TempDir()
+	client := New(&fakeProvider{}, dir, 0, time.Hour)
+	for i, duration := range []int{200000, 260000} {
+		query := lyrics.Query{Artist: "Artist", Track: "Track", Duration: duration}
+		err := writeEntry(client.path(query), &Entry{
+			Artist:   query.Artist,
+			Track:    query.Track,
+			Duration: duration,
+			Time:     time.Now().Add(time.Duration(i-2) * time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry, err := Get(dir, "artist", "track")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil || entry.Duration != 260000 {
+		t.Errorf("Get() = %+v; want the latest entry", entry)
+	}
+
+	if err := Remove(dir, "Artist", "Track"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(dir); len(entries) != 0 {
+		t.Errorf("List() = %+v; want none", entries)
+	}
+	if err := Remove(dir, "Artist", "Track"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Remove() = %v; want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestKey(t *testing.T) {
+	type track struct {
+		artist, track string
+		duration      int
+	}
+	tests := []struct {
+		a, b track
+		same bool
+	}{
+		{track{"Queen", "Bohemian Rhapsody", 0}, track{" QUEEN ", "Bohemian  Rhapsody", 0}, true},
+		{track{"Beyoncé", "Halo", 0}, track{"beyonce", "halo", 0}, true},
+		{track{"Queen", "Bohemian Rhapsody", 0}, track{"Queen", "Bohemian Rhapsody (Live)", 0}, false},
+		{track{"Queen", "Bohemian Rhapsody", 0}, track{"Queen", "Bohemian Rhapsody - Remastered 2011", 0}, false},
+		{track{"Queen", "Bohemian Rhapsody", 354000}, track{"Queen", "Bohemian Rhapsody", 355200}, true},
+		{track{"Queen", "Bohemian Rhapsody", 354000}, track{"Queen", "Bohemian Rhapsody", 409000}, false},
+		{track{"Queen", "Bohemian Rhapsody", 354000}, track{"Queen", "Bohemian Rhapsody", 0}, false},
+	}
+
+	for _, tt := range tests {
+		same := Key(tt.a.artist, tt.a.track, tt.a.duration) == Key(tt.b.artist, tt.b.track, tt.b.duration)
+		if same != tt.same {
+			t.Errorf("Key(%+v) == Key(%+v) is %v; want %v", tt.a, tt.b, same, tt.same)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for name, age := range map[string]time.Duration{
+		// written under a key of an older version
+		"0123456789abcdef0123456789abcdef01234567.json": 48 * time.Hour,
+		Key("Artist", "Old", 0) + ".json":               48 * time.Hour,
+		Key("Artist", "New", 0) + ".json":               time.Hour,
+	} {
+		err := writeEntry(filepath.Join(dir, name), &Entry{
+			Artist: "Artist",
+			Track:  "Track",
+			Time:   time.Now().Add(-age),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := Prune(dir, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Prune() = %d; want 2", removed)
+	}
+	if entries, _ := List(dir); len(entries) != 1 {
+		t.Errorf("cache has %d entries; want 1", len(entries))
+	}
+}
@@ -0,0 +1,143 @@
+package embedded
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/local"
+)
+
+// maxTagSize limits the size of the tags we are willing to read.
+const maxTagSize = 16 << 20
+
+var errInvalidTag = errors.New("invalid tag")
+
+// New returns a provider that reads lyrics embedded in the file being
+// played. Relative paths are resolved against musicDir.
+func New(musicDir string) *Client {
+	if strings.HasPrefix(musicDir, "~/") {
+		dirname, _ := os.UserHomeDir()
+		musicDir = filepath.Join(dirname, musicDir[2:])
+	}
+	return &Client{musicDir: musicDir}
+}
+
+// Client implements lyrics.Provider
+type Client struct {
+	musicDir string
+}
+
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	path := LocalPath(c.musicDir, query.File)
+	if path == "" {
+		return nil, nil
+	}
+	result, err := Read(path)
+	if result != nil {
+		result.URL = local.FileURL(path)
+	}
+	return result, err
+}
+
+// LocalPath returns the local path of the file being played, if there
+// is one. Relative paths are resolved against musicDir.
+func LocalPath(musicDir, file string) string {
+	if file == "" {
+		return ""
+	}
+	if strings.Contains(file, "://") {
+		u, err := url.Parse(file)
+		if err != nil || u.Scheme != "file" {
+			return ""
+		}
+		return filepath.FromSlash(u.Path)
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	if musicDir == "" {
+		return ""
+	}
+	return filepath.Join(musicDir, filepath.FromSlash(file))
+}
+
+// Read returns lyrics embedded in the audio file: ID3v2 SYLT/USLT frames
+// (MP3) or LYRICS/UNSYNCEDLYRICS Vorbis comments (FLAC, Ogg Vorbis, Opus).
+// It returns nil if there are no lyrics or the format isn't supported.
+func Read(path string) (*lyrics.Lyrics, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	magic, err := reader.Peek(4)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if bytes.HasPrefix(magic, []byte("ID3")) {
+		result, err := readID3(reader)
+		if err != nil || result != nil {
+			return result, err
+		}
+		// FLAC files may have ID3 tags too
+		if magic, err = reader.Peek(4); err != nil {
+			return nil, nil
+		}
+	}
+
+	switch string(magic) {
+	case "fLaC":
+		return readFLAC(reader)
+	case "OggS":
+		return readOgg(reader)
+	}
+	return nil, nil
+}
+
+// parseText parses lyrics that may be either LRC or plain text.
+func parseText(text string) *lyrics.Lyrics {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil
+	}
+
+	parts := strings.Split(text, "\n")
+	for _, part := range parts {
+		if !lyrics.IsTimestampLine(strings.TrimSpace(part)) {
+			continue
+		}
+		lrc, err := lyrics.ParseLRC(strings.NewReader(text))
+		if err != nil || len(lrc.Lines) == 0 {
+			break
+		}
+		return &lyrics.Lyrics{Lines: lrc.Lines, Synced: true}
+	}
+
+	result := make([]lyrics.Line, len(parts))
+	for i, part := range parts {
+		result[i] = lyrics.Line{Words: strings.TrimSpace(part)}
+	}
+	return &lyrics.Lyrics{Lines: result}
+}
+
+// better returns the synced lyrics, if any, or the first non-nil.
+func better(a, b *lyrics.Lyrics) *lyrics.Lyrics {
+	if a == nil || (!a.Synced && b != nil && b.Synced) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,224 @@
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/local"
+)
+
+func TestReadID3(t *testing.T) {
+	sylt := []byte{encodingUTF16, 'e', 'n', 'g', timestampMs, 1}
+	sylt = append(sylt, utf16le("")...)
+	for _, e := range []struct {
+		text string
+		time uint32
+	}{
+		{"First ", 1000},
+		{"line", 1500},
+		{"\nSecond line", 3000},
+	} {
+		sylt = append(sylt, utf16le(e.text)...)
+		sylt = binary.BigEndian.AppendUint32(sylt, e.time)
+	}
+	uslt := append([]byte{encodingLatin1, 'e', 'n', 'g', 0}, "Plain\nlyrics"...)
+
+	path := writeFile(t, "song.mp3", id3v23(
+		id3v23Frame("TIT2", append([]byte{encodingLatin1}, "Title"...)),
+		id3v23Frame("USLT", uslt),
+		id3v23Frame("SYLT", sylt),
+	))
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lyrics.Line{
+		{Time: 1000, Words: "First line", Syllables: []lyrics.Syllable{
+			{Time: 1000, Text: "First "},
+			{Time: 1500, Text: "line"},
+		}},
+		{Time: 3000, Words: "Second line"},
+	}
+	if !reflect.DeepEqual(result.Lines, expected) {
+		t.Errorf("Read() = %+v; want %+v", result.Lines, expected)
+	}
+	if result.Synced != true {
+		t.Errorf("Read().Synced = %v; want true", result.Synced)
+	}
+}
+
+func TestReadID3LrcInUSLT(t *testing.T) {
+	uslt := append([]byte{encodingUTF8, 'e', 'n', 'g', 0}, "[00:01.00]First\r\n[00:02.00]Second"...)
+	path := writeFile(t, "song.mp3", id3v23(id3v23Frame("USLT", uslt)))
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lyrics.Line{
+		{Time: 1000, Words: "First"},
+		{Time: 2000, Words: "Second"},
+	}
+	if !reflect.DeepEqual(result.Lines, expected) {
+		t.Errorf("Read() = %+v; want %+v", result.Lines, expected)
+	}
+	if result.Synced != true {
+		t.Errorf("Read().Synced = %v; want true", result.Synced)
+	}
+}
+
+func TestReadFLAC(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("fLaC")
+	// STREAMINFO
+	data.Write([]byte{0, 0, 0, 34})
+	data.Write(make([]byte, 34))
+	// VORBIS_COMMENT
+	comments := vorbisComments("TITLE=Title", "unsyncedlyrics=Plain", "LYRICS=[00:01.00]Synced")
+	data.Write([]byte{0x80 | flacVorbisComment, 0, byte(len(comments) >> 8), byte(len(comments))})
+	data.Write(comments)
+
+	result, err := Read(writeFile(t, "song.flac", data.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lyrics.Line{{Time: 1000, Words: "Synced"}}
+	if !reflect.DeepEqual(result.Lines, expected) {
+		t.Errorf("Read() = %+v; want %+v", result.Lines, expected)
+	}
+	if result.Synced != true {
+		t.Errorf("Read().Synced = %v; want true", result.Synced)
+	}
+}
+
+func TestReadOpus(t *testing.T) {
+	head := []byte("OpusHead")
+	tags := append([]byte("OpusTags"), vorbisComments("LYRICS=First\nSecond")...)
+
+	var data bytes.Buffer
+	data.Write(oggPage(head))
+	data.Write(oggPage(tags))
+
+	result, err := Read(writeFile(t, "song.opus", data.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lyrics.Line{{Words: "First"}, {Words: "Second"}}
+	if !reflect.DeepEqual(result.Lines, expected) {
+		t.Errorf("Read() = %+v; want %+v", result.Lines, expected)
+	}
+	if result.Synced != false {
+		t.Errorf("Read().Synced = %v; want false", result.Synced)
+	}
+}
+
+func TestReadUnsupported(t *testing.T) {
+	result, err := Read(writeFile(t, "song.wav", []byte("RIFF....WAVE")))
+	if err != nil || result != nil {
+		t.Errorf("Read() = %+v, %v; want nil, nil", result, err)
+	}
+}
+
+func TestLyrics(t *testing.T) {
+	uslt := append([]byte{encodingUTF8, 'e', 'n', 'g', 0}, "[00:01.00]embedded"...)
+	path := writeFile(t, "song.mp3", id3v23(id3v23Frame("USLT", uslt)))
+	dir := filepath.Dir(path)
+
+	client := New(dir)
+
+	tests := []struct {
+		name     string
+		file     string
+		expected []lyrics.Line
+	}{
+		{"relative", "song.mp3", []lyrics.Line{{Time: 1000, Words: "embedded"}}},
+		{"absolute", path, []lyrics.Line{{Time: 1000, Words: "embedded"}}},
+		{"uri", "file://" + filepath.ToSlash(path), []lyrics.Line{{Time: 1000, Words: "embedded"}}},
+		{"stream", "http://example.com/song.mp3", nil},
+		{"unknown", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Lyrics(context.Background(), lyrics.Query{File: tt.file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []lyrics.Line
+			if result != nil {
+				lines = result.Lines
+				if url := local.FileURL(path); result.URL != url {
+					t.Errorf("Lyrics(%q).URL = %q; want %q", tt.file, result.URL, url)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("Lyrics(%q) = %+v; want %+v", tt.file, lines, tt.expected)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func utf16le(s string) []byte {
+	b := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return append(b, 0, 0)
+}
+
+func id3v23(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	return append(header, body...)
+}
+
+func id3v23Frame(id string, body []byte) []byte {
+	frame := append([]byte(id), 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(body)))
+	return append(frame, body...)
+}
+
+func vorbisComments(fields ...string) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, 6)
+	b = append(b, "vendor"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(fields)))
+	for _, f := range fields {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(f)))
+		b = append(b, f...)
+	}
+	return b
+}
+
+func oggPage(packet []byte) []byte {
+	var segments []byte
+	for n := len(packet); ; n -= 255 {
+		if n < 255 {
+			segments = append(segments, byte(n))
+			break
+		}
+		segments = append(segments, 255)
+	}
+	header := make([]byte, 27)
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint32(header[14:], 1)
+	header[26] = byte(len(segments))
+	return append(append(header, segments...), packet...)
+}
@@ -0,0 +1,268 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+// ID3v2 text encodings
+const (
+	encodingLatin1 = iota
+	encodingUTF16
+	encodingUTF16BE
+	encodingUTF8
+)
+
+// SYLT timestamp format using milliseconds
+const timestampMs = 2
+
+// readID3 reads SYLT and USLT frames from the ID3v2 tag,
+// leaving the reader right after the tag.
+func readID3(r io.Reader) (*lyrics.Lyrics, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errInvalidTag
+	}
+	version, flags := header[3], header[5]
+	size := syncsafe(header[6:10])
+	if size > maxTagSize {
+		return nil, errInvalidTag
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errInvalidTag
+	}
+	if version == 4 && flags&0x10 != 0 {
+		// footer
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, errInvalidTag
+		}
+	}
+	if version < 2 || version > 4 {
+		return nil, nil
+	}
+
+	if version < 4 && flags&0x80 != 0 {
+		data = unsynchronise(data)
+	}
+	if version >= 3 && flags&0x40 != 0 && len(data) >= 4 {
+		// extended header
+		extSize := syncsafe(data[:4])
+		if version == 3 {
+			extSize = int(binary.BigEndian.Uint32(data[:4])) + 4
+		}
+		if extSize > len(data) {
+			return nil, errInvalidTag
+		}
+		data = data[extSize:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	var synced, unsynced *lyrics.Lyrics
+	for len(data) >= headerLen && data[0] != 0 {
+		id := string(data[:idLen])
+
+		var size int
+		var frameFlags byte
+		switch version {
+		case 2:
+			size = int(data[3])<<16 | int(data[4])<<8 | int(data[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(data[4:8]))
+			frameFlags = data[9]
+		case 4:
+			size = syncsafe(data[4:8])
+			frameFlags = data[9]
+		}
+		if size < 0 || size > len(data)-headerLen {
+			break
+		}
+		body := data[headerLen : headerLen+size]
+		data = data[headerLen+size:]
+
+		body, ok := frameBody(version, frameFlags, body)
+		if !ok {
+			continue
+		}
+
+		switch id {
+		case "SYLT", "SLT":
+			synced = better(synced, parseSYLT(body))
+		case "USLT", "ULT":
+			unsynced = better(unsynced, parseUSLT(body))
+		}
+	}
+	return better(synced, unsynced), nil
+}
+
+// frameBody removes extra data from the frame according to its flags.
+// It returns false for compressed or encrypted frames.
+func frameBody(version, flags byte, body []byte) ([]byte, bool) {
+	switch version {
+	case 3:
+		if flags&0xc0 != 0 {
+			return nil, false
+		}
+		if flags&0x20 != 0 && len(body) > 0 {
+			// grouping identity
+			body = body[1:]
+		}
+	case 4:
+		if flags&0x0c != 0 {
+			return nil, false
+		}
+		if flags&0x40 != 0 && len(body) > 0 {
+			// grouping identity
+			body = body[1:]
+		}
+		if flags&0x01 != 0 && len(body) >= 4 {
+			// data length indicator
+			body = body[4:]
+		}
+		if flags&0x02 != 0 {
+			body = unsynchronise(body)
+		}
+	}
+	return body, len(body) != 0
+}
+
+// parseSYLT parses synchronised lyrics. Entries starting with a newline
+// begin new lines; if there are such entries, the rest become syllables.
+func parseSYLT(b []byte) *lyrics.Lyrics {
+	if len(b) < 6 {
+		return nil
+	}
+	encoding, format := b[0], b[4]
+	if format != timestampMs {
+		// MPEG frames are not supported
+		return nil
+	}
+	_, rest := splitText(encoding, b[6:])
+
+	type entry struct {
+		text string
+		time int
+	}
+	var (
+		entries  []entry
+		newlines bool
+	)
+	for len(rest) != 0 {
+		var text []byte
+		text, rest = splitText(encoding, rest)
+		if len(rest) < 4 {
+			break
+		}
+		e := entry{
+			text: decodeText(encoding, text),
+			time: int(binary.BigEndian.Uint32(rest[:4])),
+		}
+		rest = rest[4:]
+
+		if strings.HasPrefix(e.text, "\n") || strings.HasPrefix(e.text, "\r") {
+			newlines = true
+		}
+		entries = append(entries, e)
+	}
+
+	var result []lyrics.Line
+	for _, e := range entries {
+		text := e.text
+		if !newlines || len(result) == 0 || strings.TrimLeft(text, "\r\n") != text {
+			text = strings.TrimLeft(text, "\r\n")
+			result = append(result, lyrics.Line{Time: e.time})
+		}
+		line := &result[len(result)-1]
+		line.Words += text
+		if newlines {
+			line.Syllables = append(line.Syllables, lyrics.Syllable{
+				Time: e.time,
+				Text: text,
+			})
+		}
+	}
+	for i := range result {
+		result[i].Words = strings.TrimSpace(result[i].Words)
+		if len(result[i].Syllables) < 2 {
+			result[i].Syllables = nil
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	lyrics.SortLines(result)
+	return &lyrics.Lyrics{Lines: result, Synced: true}
+}
+
+// parseUSLT parses unsynchronised lyrics, which may contain LRC.
+func parseUSLT(b []byte) *lyrics.Lyrics {
+	if len(b) < 4 {
+		return nil
+	}
+	encoding := b[0]
+	_, text := splitText(encoding, b[4:])
+	return parseText(decodeText(encoding, text))
+}
+
+// splitText splits the null-terminated string from the rest of data.
+func splitText(encoding byte, b []byte) (text, rest []byte) {
+	if encoding == encodingUTF16 || encoding == encodingUTF16BE {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:]
+			}
+		}
+		return b, nil
+	}
+
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		return b[:i], b[i+1:]
+	}
+	return b, nil
+}
+
+func decodeText(encoding byte, b []byte) string {
+	switch encoding {
+	case encodingLatin1:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	case encodingUTF16, encodingUTF16BE:
+		var order binary.ByteOrder = binary.BigEndian
+		if encoding == encodingUTF16 && len(b) >= 2 {
+			if b[0] == 0xff && b[1] == 0xfe {
+				order = binary.LittleEndian
+				b = b[2:]
+			} else if b[0] == 0xfe && b[1] == 0xff {
+				b = b[2:]
+			}
+		}
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = order.Uint16(b[i*2:])
+		}
+		return string(utf16.Decode(units))
+	}
+	return string(b)
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// unsynchronise reverts ID3 unsynchronisation (0xFF 0x00 -> 0xFF).
+func unsynchronise(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
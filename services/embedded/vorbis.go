@@ -0,0 +1,161 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+// FLAC metadata block type of Vorbis comments
+const flacVorbisComment = 4
+
+// comment fields that may contain lyrics, in order of preference
+var commentFields = []string{"LYRICS", "UNSYNCEDLYRICS"}
+
+// readFLAC reads lyrics from the VORBIS_COMMENT metadata block.
+func readFLAC(r io.Reader) (*lyrics.Lyrics, error) {
+	header := make([]byte, 4)
+	// "fLaC"
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errInvalidTag
+	}
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, errInvalidTag
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7f
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if blockType == flacVorbisComment {
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, errInvalidTag
+			}
+			return parseComments(data), nil
+		}
+		if last {
+			return nil, nil
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+			return nil, errInvalidTag
+		}
+	}
+}
+
+// readOgg reads lyrics from the comment header of Ogg Vorbis or Opus,
+// which is the second packet of the first logical stream.
+func readOgg(r io.Reader) (*lyrics.Lyrics, error) {
+	var (
+		header  = make([]byte, 27)
+		packets [][]byte
+		packet  []byte
+		serial  uint32
+		total   int
+	)
+	for first := true; len(packets) < 2; first = false {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, errInvalidTag
+		}
+		if !bytes.HasPrefix(header, []byte("OggS")) {
+			return nil, errInvalidTag
+		}
+
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return nil, errInvalidTag
+		}
+		var size int
+		for _, s := range segments {
+			size += int(s)
+		}
+		total += size
+		if total > maxTagSize {
+			return nil, errInvalidTag
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errInvalidTag
+		}
+
+		pageSerial := binary.LittleEndian.Uint32(header[14:18])
+		if first {
+			serial = pageSerial
+		} else if pageSerial != serial {
+			// page of another stream
+			continue
+		}
+
+		var offset int
+		for _, s := range segments {
+			packet = append(packet, data[offset:offset+int(s)]...)
+			offset += int(s)
+			if s < 255 {
+				packets = append(packets, packet)
+				packet = nil
+			}
+		}
+	}
+
+	comment := packets[1]
+	switch {
+	case bytes.HasPrefix(comment, []byte("OpusTags")):
+		comment = comment[8:]
+	case bytes.HasPrefix(comment, []byte("\x03vorbis")):
+		comment = comment[7:]
+	default:
+		return nil, nil
+	}
+	return parseComments(comment), nil
+}
+
+// parseComments parses Vorbis comments and returns lyrics from them.
+func parseComments(b []byte) *lyrics.Lyrics {
+	next := func() ([]byte, bool) {
+		if len(b) < 4 {
+			return nil, false
+		}
+		size := int(binary.LittleEndian.Uint32(b))
+		if size < 0 || size > len(b)-4 {
+			return nil, false
+		}
+		field := b[4 : 4+size]
+		b = b[4+size:]
+		return field, true
+	}
+
+	// vendor string
+	if _, ok := next(); !ok || len(b) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+
+	fields := map[string]string{}
+	for i := 0; i < count; i++ {
+		field, ok := next()
+		if !ok {
+			break
+		}
+		key, value, ok := strings.Cut(string(field), "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToUpper(key)
+		if _, exists := fields[key]; !exists {
+			fields[key] = value
+		}
+	}
+
+	var result *lyrics.Lyrics
+	for _, key := range commentFields {
+		if value, ok := fields[key]; ok {
+			result = better(result, parseText(value))
+		}
+	}
+	return result
+}
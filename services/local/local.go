@@ -2,124 +2,330 @@ package local
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/normalize"
 )
 
-var replacer = strings.NewReplacer(
-	"_", " ", "-", " ",
-	",", "", ".", "",
-	"!", "", "?", "",
-	"(", "", ")", "",
-	"[", "", "]", "",
-)
+// Extensions of supported lyrics files.
+var Extensions = []string{".lrc", ".srt", ".vtt", ".ttml"}
+
+// minScore is the minimum score of a file to be used.
+const minScore = 0.7
 
 type file struct {
 	Path      string
 	NameParts []string
+	// words of the parent folders, like "Artist/Album/Track.lrc"
+	Dirs [][]string
+
+	// words of the [ar:], [ti:] and [al:] tags
+	Artist []string
+	Title  []string
+	Album  []string
 }
 
+// New indexes the folder and keeps the index up to date.
 func New(folder string) (*Client, error) {
-	index, err := createIndex(folder)
-	if err != nil {
+	if strings.HasPrefix(folder, "~/") {
+		dirname, _ := os.UserHomeDir()
+		folder = filepath.Join(dirname, folder[2:])
+	}
+
+	c := &Client{folder: folder, index: map[string]*file{}}
+	// lyrics can still be found without watching
+	if watcher, err := fsnotify.NewWatcher(); err == nil {
+		c.watcher = watcher
+	}
+	if err := c.addDir(folder); err != nil {
+		c.Close()
 		return nil, err
 	}
-	return &Client{index: index}, nil
+	if c.watcher != nil {
+		go c.watch()
+	}
+	return c, nil
 }
 
 // Client implements lyrics.Provider
 type Client struct {
-	index []*file
+	folder  string
+	mu      sync.RWMutex
+	index   map[string]*file
+	watcher *fsnotify.Watcher
 }
 
-func (c *Client) Lyrics(artist, track string) ([]lyrics.Line, error) {
-	query := artist + " " + track
-	f := c.findFile(query)
-	if f == nil {
-		return nil, nil
+// Close stops watching the folder.
+func (c *Client) Close() error {
+	if c.watcher == nil {
+		return nil
 	}
+	return c.watcher.Close()
+}
 
-	reader, err := os.Open(f.Path)
-	if err != nil {
-		return nil, err
-	}
-	defer reader.Close()
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	for {
+		f := c.findFile(query)
+		if f == nil {
+			return nil, nil
+		}
 
-	return parseLrcFile(reader), nil
+		result, err := ReadFile(f.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			// removed, but not yet reported by the watcher
+			c.remove(f.Path)
+			continue
+		}
+		return result, err
+	}
 }
 
-func (c *Client) findFile(query string) *file {
-	parts := splitString(query)
+func (c *Client) findFile(query lyrics.Query) *file {
+	var (
+		artist = normalize.Words(query.Artist)
+		track  = normalize.Words(query.Track)
+		album  = normalize.Words(query.Album)
+		all    = append(slices.Clip(artist), track...)
+	)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
 	var best *file
-	var maxScore int
+	var maxScore float64
 	for _, f := range c.index {
-		var score int
-		for _, part := range parts {
-			for _, namePart := range f.NameParts {
-				if namePart == part {
-					score++
-					break
-				}
-			}
+		var score float64
+		switch {
+		case f.Artist != nil && f.Title != nil && artist != nil:
+			score = (normalize.Similarity(artist, f.Artist) + normalize.Similarity(track, f.Title)) / 2
+		case f.Artist != nil && f.Title != nil:
+			score = normalize.Similarity(all, append(slices.Clip(f.Artist), f.Title...))
+		default:
+			score = nameScore(f, artist, track, all)
 		}
-		if score > maxScore {
+		if score < minScore {
+			continue
+		}
+		if album != nil && f.Album != nil {
+			// to choose between tracks with the same name
+			score += normalize.Similarity(album, f.Album) / 10
+		}
+		if score > maxScore || score == maxScore && f.Path < best.Path {
 			maxScore = score
 			best = f
-			if score == len(parts) {
-				break
-			}
 		}
 	}
 	return best
 }
 
-func createIndex(folder string) ([]*file, error) {
-	if strings.HasPrefix(folder, "~/") {
-		dirname, _ := os.UserHomeDir()
-		folder = filepath.Join(dirname, folder[2:])
+// nameScore rates the file without tags by its name and folders.
+func nameScore(f *file, artist, track, all []string) float64 {
+	score := normalize.Similarity(all, f.NameParts)
+	if artist == nil {
+		return score
+	}
+	if f.Dirs == nil && slices.Equal(track, f.NameParts) {
+		// the name is the track, and there is nothing to tell the artist
+		score = max(score, minScore)
+	}
+	for _, dir := range f.Dirs {
+		score = max(score, (normalize.Similarity(artist, dir)+normalize.Similarity(track, f.NameParts))/2)
 	}
+	return score
+}
 
-	index := []*file{}
-	return index, filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+// addDir indexes the files in the folder and watches its subfolders.
+func (c *Client) addDir(folder string) error {
+	return filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
 		if d == nil {
 			return fmt.Errorf("invalid path: %s", path)
 		}
-		if d.IsDir() || !strings.HasSuffix(d.Name(), ".lrc") {
+		if d.IsDir() {
+			if c.watcher != nil {
+				// not being able to watch is not a reason to fail
+				c.watcher.Add(path)
+			}
 			return nil
 		}
-		name := strings.TrimSuffix(d.Name(), ".lrc")
-		parts := splitString(name)
-
-		index = append(index, &file{
-			Path:      path,
-			NameParts: parts,
-		})
+		c.addFile(path)
 		return nil
 	})
 }
 
-func splitString(s string) []string {
-	s = strings.ToLower(s)
-	s = replacer.Replace(s)
-	return strings.Fields(s)
+func (c *Client) addFile(path string) {
+	if !slices.Contains(Extensions, strings.ToLower(filepath.Ext(path))) {
+		return
+	}
+	f := indexFile(c.folder, path)
+
+	c.mu.Lock()
+	c.index[path] = f
+	c.mu.Unlock()
+}
+
+// remove removes the file or all the files in the folder from the index.
+func (c *Client) remove(path string) {
+	prefix := path + string(filepath.Separator)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for p := range c.index {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(c.index, p)
+		}
+	}
+}
+
+// indexFile reads the tags of LRC files. The name of the
+// file and its folders are used if the tags are missing.
+func indexFile(root, path string) *file {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	f := &file{
+		Path:      path,
+		NameParts: nameWords(name),
+		Dirs:      dirWords(root, path),
+	}
+	if strings.ToLower(filepath.Ext(path)) != ".lrc" {
+		return f
+	}
+
+	lrc, err := readHeader(path)
+	if err != nil {
+		return f
+	}
+	f.Artist = normalize.Words(lrc.Artist)
+	f.Title = normalize.Words(lrc.Title)
+	f.Album = normalize.Words(lrc.Album)
+	return f
 }
 
-func parseLrcFile(reader io.Reader) []lyrics.Line {
-	result := []lyrics.Line{}
+// readHeader parses the tags of the LRC file
+// without reading the lines after them.
+func readHeader(path string) (*lyrics.LRC, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var header strings.Builder
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if !lyrics.IsTimestampLine(line) {
-			continue
+		if lyrics.IsTimestampLine(strings.TrimSpace(line)) {
+			break
+		}
+		header.WriteString(line)
+		header.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lyrics.ParseLRC(strings.NewReader(header.String()))
+}
+
+// nameWords normalizes the parts of "Artist - Track" separately,
+// so that "feat." in the artist doesn't remove the track.
+func nameWords(name string) []string {
+	var words []string
+	for _, part := range strings.Split(name, " - ") {
+		words = append(words, normalize.Words(part)...)
+	}
+	if len(words) > 1 && isNumber(words[0]) {
+		// track number, like "03 - Track"
+		words = words[1:]
+	}
+	return words
+}
+
+// dirWords returns the words of the two closest folders of
+// the file inside the root, which usually are the album and
+// the artist.
+func dirWords(root, path string) [][]string {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return nil
+	}
+	var dirs [][]string
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := len(parts) - 1; i >= 0 && i >= len(parts)-2; i-- {
+		if words := normalize.Words(parts[i]); words != nil {
+			dirs = append(dirs, words)
+		}
+	}
+	return dirs
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ReadFile parses the lyrics file according to its extension.
+func ReadFile(path string) (*lyrics.Lyrics, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := &lyrics.Lyrics{URL: FileURL(path)}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		result.Lines, err = lyrics.ParseSRT(reader)
+	case ".vtt":
+		result.Lines, err = lyrics.ParseVTT(reader)
+	case ".ttml":
+		result.Lines, err = lyrics.ParseTTML(reader)
+	default:
+		var lrc *lyrics.LRC
+		lrc, err = lyrics.ParseLRC(reader)
+		if err == nil {
+			result.Lines = lrc.Lines
+			result.Artist = lrc.Artist
+			result.Title = lrc.Title
+			result.Language = lrcLanguage(lrc)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the formats are timed, and only lines
+	// with timestamps are read from LRC
+	result.Synced = len(result.Lines) != 0
+	return result, nil
+}
+
+// FileURL returns the file:// URL of the path.
+func FileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// lrcLanguage returns the value of the non-standard [la:] tag.
+func lrcLanguage(lrc *lyrics.LRC) string {
+	for _, tag := range lrc.Tags {
+		if tag.Key == "la" || tag.Key == "lang" {
+			return tag.Value
 		}
-		result = append(result, lyrics.ParseLrcLine(line))
 	}
-	return result
+	return ""
 }
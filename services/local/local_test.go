@@ -0,0 +1,196 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+var files = map[string]string{
+	"Crystal Castles - Kerosene.lrc":          "[00:01.00]Kerosene\n",
+	"Death Grips - No Love.srt":               "1\n00:00:01,000 --> 00:00:02,000\nNo Love\n",
+	"grimes/visions/Intro.lrc":                "[ar:Grimes]\n[ti:Intro]\n[al:Visions]\n[00:01.00]Visions\n",
+	"grimes/art angels/Intro.lrc":             "[ar:Grimes]\n[ti:Intro]\n[al:Art Angels]\n[00:01.00]Art Angels\n",
+	"downloads/track01.lrc":                   "[ar:The Weeknd]\n[ti:Blinding Lights]\n[la:en]\n[00:01.00]Blinding Lights\n[00:02.00]\n",
+	"downloads/The Weeknd - Intro.lrc":        "[00:01.00]Not by Grimes\n",
+	"Beyonce feat. Jay-Z - Crazy in Love.lrc": "[00:01.00]Crazy in Love\n",
+	"queen/live.lrc":                          "[ar:Queen]\n[ti:Bohemian Rhapsody (Live)]\n[00:01.00]Bohemian Rhapsody\n",
+	"Crystal Castles/II/Celestica.lrc":        "[00:01.00]Celestica\n",
+	"03 - Crimewave.lrc":                      "[00:01.00]Crimewave\n",
+}
+
+func createFolder(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLyrics(t *testing.T) {
+	client, err := New(createFolder(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query    lyrics.Query
+		expected string
+	}{
+		// file name
+		{lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene"}, "Kerosene"},
+		{lyrics.Query{Track: "Crystal Castles - Kerosene"}, "Kerosene"},
+		{lyrics.Query{Artist: "Death Grips", Track: "No Love"}, "No Love"},
+		// tags are preferred over the file name
+		{lyrics.Query{Artist: "The Weeknd", Track: "Blinding Lights"}, "Blinding Lights"},
+		{lyrics.Query{Artist: "Grimes", Track: "Intro", Album: "Art Angels"}, "Art Angels"},
+		{lyrics.Query{Artist: "Grimes", Track: "Intro", Album: "Visions"}, "Visions"},
+		// names are normalized
+		{lyrics.Query{Artist: "Beyoncé", Track: "Crazy In Love (feat. Jay-Z)"}, "Crazy in Love"},
+		{lyrics.Query{Artist: "QUEEN", Track: "Bohemian Rhapsody - Remastered 2011"}, "Bohemian Rhapsody"},
+		// folders and the name of the track only
+		{lyrics.Query{Artist: "Crystal Castles", Track: "Celestica"}, "Celestica"},
+		{lyrics.Query{Artist: "Crystal Castles", Track: "Crimewave"}, "Crimewave"},
+		{lyrics.Query{Track: "Crimewave"}, "Crimewave"},
+		{lyrics.Query{Artist: "Grimes", Track: "Celestica"}, ""},
+		// a single common word is not enough
+		{lyrics.Query{Artist: "Crystal Castles", Track: "Intro"}, ""},
+		{lyrics.Query{Artist: "Grimes", Track: "Kerosene"}, ""},
+	}
+
+	for _, tt := range tests {
+		l, err := client.Lyrics(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("Lyrics(%+v): %v", tt.query, err)
+		}
+		var result string
+		if l != nil {
+			result = l.Lines[0].Words
+		}
+		if result != tt.expected {
+			t.Errorf("Lyrics(%+v) = %q; want %q", tt.query, result, tt.expected)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := createFolder(t)
+
+	tests := []struct {
+		name     string
+		expected lyrics.Lyrics
+	}{
+		{
+			name: "downloads/track01.lrc",
+			expected: lyrics.Lyrics{
+				Lines: []lyrics.Line{
+					{Time: 1000, Words: "Blinding Lights"},
+					{Time: 2000},
+				},
+				Synced:   true,
+				Language: "en",
+				Artist:   "The Weeknd",
+				Title:    "Blinding Lights",
+			},
+		},
+		{
+			// a single line is still synced
+			name: "Crystal Castles - Kerosene.lrc",
+			expected: lyrics.Lyrics{
+				Lines:  []lyrics.Line{{Time: 1000, Words: "Kerosene"}},
+				Synced: true,
+			},
+		},
+		{
+			name: "Death Grips - No Love.srt",
+			expected: lyrics.Lyrics{
+				Lines: []lyrics.Line{
+					{Time: 1000, Words: "No Love"},
+					{Time: 2000},
+				},
+				Synced: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		result, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%q): %v", tt.name, err)
+		}
+		tt.expected.URL = FileURL(path)
+		if !reflect.DeepEqual(*result, tt.expected) {
+			t.Errorf("ReadFile(%q) = %+v; want %+v", tt.name, *result, tt.expected)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := createFolder(t)
+	client, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	query := lyrics.Query{Artist: "Grimes", Track: "Oblivion"}
+	found := func() bool {
+		l, err := client.Lyrics(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l != nil
+	}
+	waitFor := func(expected bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for found() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("Lyrics(%+v) found = %v; want %v", query, !expected, expected)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// a new folder with a file
+	folder := filepath.Join(dir, "grimes", "new")
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(folder, "Grimes - Oblivion.lrc")
+	if err := os.WriteFile(path, []byte("[00:01.00]Oblivion\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(true)
+
+	// renamed
+	renamed := filepath.Join(folder, "Grimes - Genesis.lrc")
+	if err := os.Rename(path, renamed); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(false)
+
+	// tags changed
+	if err := os.WriteFile(renamed, []byte("[ar:Grimes]\n[ti:Oblivion]\n[00:01.00]Oblivion\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(true)
+
+	// folder removed
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(false)
+}
@@ -0,0 +1,47 @@
+package local
+
+import (
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watch updates the index until the watcher is closed.
+func (c *Client) watch() {
+	for {
+		select {
+		case event, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			c.handle(event)
+		case _, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) handle(event fsnotify.Event) {
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			// files could be created before the folder is watched
+			c.addDir(event.Name)
+			return
+		}
+		c.addFile(event.Name)
+	case event.Has(fsnotify.Write):
+		// tags might have changed
+		c.addFile(event.Name)
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// the new name is reported with a separate Create event
+		c.watcher.Remove(event.Name)
+		c.remove(event.Name)
+	}
+}
@@ -5,44 +5,72 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/normalize"
 )
 
-const userAgent = "sptlrx v1.0.0 (https://github.com/raitonoberu/sptlrx)"
+// DefaultUserAgent is sent if no other user agent is configured.
+const DefaultUserAgent = "sptlrx v1.0.0 (https://github.com/raitonoberu/sptlrx)"
 
-func New() *Client {
-	return &Client{}
+// durationTolerance is the maximum difference in ms between
+// the duration of the track and the one of a search result.
+const durationTolerance = 2000
+
+// DefaultAddress is the address of the public LRCLIB instance.
+const DefaultAddress = "https://lrclib.net"
+
+func New(address, userAgent string) *Client {
+	if address == "" {
+		address = DefaultAddress
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &Client{
+		address:   strings.TrimSuffix(address, "/"),
+		userAgent: userAgent,
+	}
 }
 
 type Client struct {
-	http http.Client
+	address   string
+	userAgent string
+	http      http.Client
 }
 
 // Client implements lyrics.Provider
-func (c *Client) Lyrics(artist, track string) ([]lyrics.Line, error) {
-	if artist != "" && track != "" {
-		return c.get(artist, track)
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	if query.Artist != "" && query.Track != "" {
+		result, err := c.get(ctx, query)
+		if err != nil || result != nil {
+			return result, err
+		}
 	}
 
-	return c.search(artist + track)
+	return c.search(ctx, query)
 }
 
-func (c *Client) get(artist, track string) ([]lyrics.Line, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	u := "https://lrclib.net/api/get?" + url.Values{
-		"artist_name": {artist},
-		"track_name":  {track},
-	}.Encode()
+func (c *Client) get(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	params := url.Values{
+		"artist_name": {query.Artist},
+		"track_name":  {query.Track},
+	}
+	if query.Album != "" {
+		params.Set("album_name", query.Album)
+	}
+	if query.Duration != 0 {
+		params.Set("duration", strconv.Itoa(query.Duration/1000))
+	}
+	u := c.address + "/api/get?" + params.Encode()
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -54,27 +82,24 @@ func (c *Client) get(artist, track string) ([]lyrics.Line, error) {
 		return nil, nil
 	}
 
-	var response lrclibTrack
+	var response Record
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
 		return nil, err
 	}
 
-	return parseTrack(response), nil
+	return c.parse(response), nil
 }
 
-func (c *Client) search(query string) ([]lyrics.Line, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	u := "https://lrclib.net/api/search?" + url.Values{
-		"q": {query},
+func (c *Client) search(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	u := c.address + "/api/search?" + url.Values{
+		"q": {strings.Join(SearchWords(query), " ")},
 	}.Encode()
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.userAgent)
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -82,46 +107,93 @@ func (c *Client) search(query string) ([]lyrics.Line, error) {
 	}
 	defer resp.Body.Close()
 
-	var response []lrclibTrack
+	var response []Record
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(response) == 0 {
-		return nil, nil
+	if t := Best(response, query); t != nil {
+		return c.parse(*t), nil
 	}
-	return parseTrack(response[0]), nil
+	return nil, nil
 }
 
-type lrclibTrack struct {
-	PlainLyrics  string `json:"plainLyrics"`
-	SyncedLyrics string `json:"syncedLyrics"`
+// parse returns the lyrics of the record with a link to them.
+func (c *Client) parse(t Record) *lyrics.Lyrics {
+	result := ParseRecord(t)
+	if result != nil && t.ID != 0 {
+		result.URL = c.address + "/api/get/" + strconv.Itoa(t.ID)
+	}
+	return result
+}
+
+// SearchWords returns the normalized words of the query for full-text
+// search. "and" is skipped, since LRCLIB doesn't replace "&" with it.
+func SearchWords(query lyrics.Query) []string {
+	words := append(normalize.Words(query.Artist), normalize.Words(query.Track)...)
+	return slices.DeleteFunc(words, func(w string) bool {
+		return w == "and"
+	})
 }
 
-func parseTrack(t lrclibTrack) []lyrics.Line {
-	if t.SyncedLyrics != "" {
-		return parseSynced(t)
+// Record is a track with lyrics as stored by LRCLIB.
+type Record struct {
+	ID           int     `json:"id"`
+	TrackName    string  `json:"trackName"`
+	ArtistName   string  `json:"artistName"`
+	AlbumName    string  `json:"albumName"`
+	Duration     float64 `json:"duration"`
+	Instrumental bool    `json:"instrumental"`
+	PlainLyrics  string  `json:"plainLyrics"`
+	SyncedLyrics string  `json:"syncedLyrics"`
+}
+
+// matchDuration reports whether the track has about the same duration.
+// Unknown durations always match.
+func matchDuration(t Record, duration int) bool {
+	if duration == 0 || t.Duration == 0 {
+		return true
 	}
-	if t.PlainLyrics != "" {
-		return parsePlain(t)
+	diff := int(t.Duration*1000) - duration
+	return diff >= -durationTolerance && diff <= durationTolerance
+}
+
+// ParseRecord returns the lyrics of the record, preferring synced ones,
+// or nil if there are none. Instrumental tracks have a single empty line.
+func ParseRecord(t Record) *lyrics.Lyrics {
+	var (
+		lines  []lyrics.Line
+		synced bool
+	)
+	switch {
+	case t.Instrumental:
+		lines = []lyrics.Line{{}}
+	case t.SyncedLyrics != "":
+		lines, synced = parseSynced(t), true
+	case t.PlainLyrics != "":
+		lines = parsePlain(t)
+	}
+	if lines == nil {
+		return nil
+	}
+	return &lyrics.Lyrics{
+		Lines:  lines,
+		Synced: synced,
+		Artist: t.ArtistName,
+		Title:  t.TrackName,
 	}
-	return nil
 }
 
-func parseSynced(r lrclibTrack) []lyrics.Line {
-	lines := strings.Split(r.SyncedLyrics, "\n")
-	result := make([]lyrics.Line, 0, len(lines))
-	for _, line := range lines {
-		if !lyrics.IsTimestampLine(line) {
-			continue
-		}
-		result = append(result, lyrics.ParseLrcLine(line))
+func parseSynced(r Record) []lyrics.Line {
+	lrc, err := lyrics.ParseLRC(strings.NewReader(r.SyncedLyrics))
+	if err != nil {
+		return nil
 	}
-	return result
+	return lrc.Lines
 }
 
-func parsePlain(r lrclibTrack) []lyrics.Line {
+func parsePlain(r Record) []lyrics.Line {
 	lines := strings.Split(r.PlainLyrics, "\n")
 	result := make([]lyrics.Line, len(lines))
 	for i, line := range lines {
@@ -0,0 +1,140 @@
+package lrclib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+func TestGet(t *testing.T) {
+	var params url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/get", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+			t.Errorf("User-Agent = %q; want %q", ua, "test-agent")
+		}
+		params = r.URL.Query()
+		json.NewEncoder(w).Encode(Record{
+			ID:           1207,
+			TrackName:    "Kerosene",
+			ArtistName:   "Crystal Castles",
+			SyncedLyrics: "[00:10.12]Take me down\n[00:12.64]To the river",
+		})
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected search request")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(server.URL+"/", "test-agent")
+	result, err := client.Lyrics(context.Background(), lyrics.Query{
+		Artist:   "Crystal Castles",
+		Track:    "Kerosene",
+		Album:    "Crystal Castles (II)",
+		Duration: 197600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantParams := url.Values{
+		"artist_name": {"Crystal Castles"},
+		"track_name":  {"Kerosene"},
+		"album_name":  {"Crystal Castles (II)"},
+		"duration":    {"197"},
+	}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v; want %v", params, wantParams)
+	}
+	want := &lyrics.Lyrics{
+		Lines: []lyrics.Line{
+			{Time: 10120, Words: "Take me down"},
+			{Time: 12640, Words: "To the river"},
+		},
+		Synced: true,
+		Artist: "Crystal Castles",
+		Title:  "Kerosene",
+		URL:    server.URL + "/api/get/1207",
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Lyrics() = %+v; want %+v", result, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/search_karaoke.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gets int
+	var q string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/get", func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		q = r.URL.Query().Get("q")
+		w.Write(fixture)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(server.URL, "")
+	tests := []struct {
+		query lyrics.Query
+		gets  int
+		q     string
+	}{
+		// falls back to search if there is no exact match
+		{lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene"}, 1, "crystal castles kerosene"},
+		// searches right away if the artist is unknown
+		{lyrics.Query{Track: "Crystal Castles - Kerosene"}, 0, "crystal castles kerosene"},
+	}
+	want := []lyrics.Line{
+		{Time: 10120, Words: "Take me down"},
+		{Time: 12640, Words: "To the river"},
+	}
+	for _, test := range tests {
+		gets = 0
+		result, err := client.Lyrics(context.Background(), test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gets != test.gets {
+			t.Errorf("Lyrics(%+v) made %d get requests; want %d", test.query, gets, test.gets)
+		}
+		if q != test.q {
+			t.Errorf("Lyrics(%+v) searched %q; want %q", test.query, q, test.q)
+		}
+		if result == nil || !reflect.DeepEqual(result.Lines, want) {
+			t.Errorf("Lyrics(%+v) = %+v; want %+v", test.query, result, want)
+		}
+	}
+}
+
+func TestSearchNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result, err := New(server.URL, "").Lyrics(context.Background(), lyrics.Query{Track: "Nothing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != nil {
+		t.Errorf("Lyrics() = %+v; want nil", result)
+	}
+}
@@ -0,0 +1,111 @@
+package lrclib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Track is the track with lyrics to publish.
+type Track struct {
+	TrackName    string `json:"trackName"`
+	ArtistName   string `json:"artistName"`
+	AlbumName    string `json:"albumName"`
+	Duration     int    `json:"duration"` // in seconds
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+// Publish uploads the lyrics to LRCLIB. It may take a while,
+// since a proof-of-work challenge has to be solved first.
+func (c *Client) Publish(ctx context.Context, track Track) error {
+	var ch challenge
+	if err := c.post(ctx, "/api/request-challenge", "", nil, &ch); err != nil {
+		return fmt.Errorf("couldn't request challenge: %w", err)
+	}
+
+	nonce, err := solveChallenge(ctx, ch.Prefix, ch.Target)
+	if err != nil {
+		return fmt.Errorf("couldn't solve challenge: %w", err)
+	}
+
+	token := ch.Prefix + ":" + nonce
+	if err := c.post(ctx, "/api/publish", token, track, nil); err != nil {
+		return fmt.Errorf("couldn't publish: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, path, token string, body, out any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.address+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Publish-Token", token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var e apiError
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Message != "" {
+			return errors.New(e.Message)
+		}
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// solveChallenge finds a nonce, such that sha256(prefix + nonce) < target.
+func solveChallenge(ctx context.Context, prefix, target string) (string, error) {
+	t, err := hex.DecodeString(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid target: %w", err)
+	}
+
+	buf := []byte(prefix)
+	for nonce := 0; ; nonce++ {
+		if nonce%100000 == 0 && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		buf = strconv.AppendInt(buf[:len(prefix)], int64(nonce), 10)
+		hash := sha256.Sum256(buf)
+		if bytes.Compare(hash[:], t) < 0 {
+			return strconv.Itoa(nonce), nil
+		}
+	}
+}
+
+type challenge struct {
+	Prefix string `json:"prefix"`
+	Target string `json:"target"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
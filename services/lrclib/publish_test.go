@@ -0,0 +1,75 @@
+package lrclib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	const (
+		prefix = "VXMwW2qPfW2gkCNSl1i708NJkDghtAyU"
+		target = "0fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	)
+	track := Track{
+		TrackName:    "Track",
+		ArtistName:   "Artist",
+		AlbumName:    "Album",
+		Duration:     205,
+		PlainLyrics:  "first\nsecond",
+		SyncedLyrics: "[00:01.00]first\n[00:02.00]second\n",
+	}
+
+	var published *Track
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/request-challenge", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(challenge{Prefix: prefix, Target: target})
+	})
+	mux.HandleFunc("POST /api/publish", func(w http.ResponseWriter, r *http.Request) {
+		p, nonce, _ := strings.Cut(r.Header.Get("X-Publish-Token"), ":")
+		hash := sha256.Sum256([]byte(p + nonce))
+		expected, _ := hex.DecodeString(target)
+		if p != prefix || bytes.Compare(hash[:], expected) >= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiError{
+				Code:    400,
+				Name:    "IncorrectPublishTokenError",
+				Message: "The provided publish token is incorrect",
+			})
+			return
+		}
+
+		published = &Track{}
+		json.NewDecoder(r.Body).Decode(published)
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(server.URL, "")
+	if err := client.Publish(context.Background(), track); err != nil {
+		t.Fatal(err)
+	}
+	if published == nil || *published != track {
+		t.Errorf("published %+v; want %+v", published, track)
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, "")
+	err := client.Publish(context.Background(), Track{})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Publish() error = %v; want status code 503", err)
+	}
+}
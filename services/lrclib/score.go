@@ -0,0 +1,93 @@
+package lrclib
+
+import (
+	"slices"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/normalize"
+)
+
+// words that mark a different version of the track
+var unwanted = []string{"karaoke", "instrumental", "cover"}
+
+// minSimilarity is the minimum similarity of the names
+// of a record to the query for it to be used.
+const minSimilarity = 0.7
+
+// Best returns the record that matches the query best, or nil
+// if no record has similar names and the right duration.
+func Best(tracks []Record, query lyrics.Query) *Record {
+	var (
+		result    *Record
+		bestScore float64
+	)
+	for i := range tracks {
+		t := &tracks[i]
+		if !matchDuration(*t, query.Duration) || nameSimilarity(*t, query) < minSimilarity {
+			continue
+		}
+		if s := score(*t, query); result == nil || s > bestScore {
+			result, bestScore = t, s
+		}
+	}
+	return result
+}
+
+// nameSimilarity returns the similarity of the names of the record
+// to the query, from 0 to 1. Without the artist the track name may
+// also contain it, like "Artist - Track".
+func nameSimilarity(t Record, query lyrics.Query) float64 {
+	if query.Artist != "" && query.Track != "" {
+		return (similarity(query.Track, t.TrackName) + similarity(query.Artist, t.ArtistName)) / 2
+	}
+	return max(
+		similarity(query.Track, t.TrackName),
+		similarity(
+			normalize.String(query.Artist)+" "+normalize.String(query.Track),
+			normalize.String(t.ArtistName)+" "+normalize.String(t.TrackName),
+		),
+	)
+}
+
+// score rates how well the search result matches the query.
+func score(t Record, query lyrics.Query) float64 {
+	var s float64
+
+	if query.Artist != "" && query.Track != "" {
+		s += 3 * similarity(query.Track, t.TrackName)
+		s += 2 * similarity(query.Artist, t.ArtistName)
+	} else {
+		s += 5 * similarity(
+			normalize.String(query.Artist)+" "+normalize.String(query.Track),
+			normalize.String(t.ArtistName)+" "+normalize.String(t.TrackName),
+		)
+	}
+
+	queryWords := append(normalize.Words(query.Artist), normalize.Words(query.Track)...)
+	for _, w := range normalize.Words(t.TrackName) {
+		if slices.Contains(unwanted, w) && !slices.Contains(queryWords, w) {
+			s -= 1
+		}
+	}
+
+	switch {
+	case t.SyncedLyrics != "":
+		s += 1
+	case t.Instrumental || t.PlainLyrics == "":
+		s -= 2
+	}
+
+	if query.Duration != 0 && t.Duration != 0 {
+		diff := t.Duration*1000 - float64(query.Duration)
+		if diff < 0 {
+			diff = -diff
+		}
+		s += 1 - diff/durationTolerance
+	}
+	return s
+}
+
+// similarity returns the Sørensen–Dice coefficient of the words of the names.
+func similarity(a, b string) float64 {
+	return normalize.Similarity(normalize.Words(a), normalize.Words(b))
+}
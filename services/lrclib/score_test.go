@@ -0,0 +1,130 @@
+package lrclib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+func TestBest(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		query    lyrics.Query
+		expected int
+	}{
+		{
+			name:     "original over karaoke and cover",
+			fixture:  "search_karaoke.json",
+			query:    lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene", Duration: 197000},
+			expected: 1208,
+		},
+		{
+			name:     "synced without duration",
+			fixture:  "search_karaoke.json",
+			query:    lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene"},
+			expected: 1208,
+		},
+		{
+			name:     "artist in track name",
+			fixture:  "search_karaoke.json",
+			query:    lyrics.Query{Track: "Crystal Castles - Kerosene"},
+			expected: 1208,
+		},
+		{
+			name:     "closest duration",
+			fixture:  "search_duration.json",
+			query:    lyrics.Query{Artist: "The Weeknd", Track: "Blinding Lights", Duration: 200500},
+			expected: 7702,
+		},
+		{
+			name:     "extended mix",
+			fixture:  "search_duration.json",
+			query:    lyrics.Query{Artist: "The Weeknd", Track: "Blinding Lights (Extended Mix)", Duration: 262000},
+			expected: 7701,
+		},
+		{
+			name:     "lyrics over instrumental",
+			fixture:  "search_instrumental.json",
+			query:    lyrics.Query{Artist: "Grimes", Track: "Genesis"},
+			expected: 9111,
+		},
+		{
+			name:     "unrelated results",
+			fixture:  "search_unrelated.json",
+			query:    lyrics.Query{Artist: "Grimes", Track: "Oblivion", Duration: 251000},
+			expected: 0,
+		},
+		{
+			name:     "unrelated results without duration",
+			fixture:  "search_unrelated.json",
+			query:    lyrics.Query{Artist: "Grimes", Track: "Oblivion"},
+			expected: 0,
+		},
+		{
+			name:     "only track name",
+			fixture:  "search_unrelated.json",
+			query:    lyrics.Query{Track: "Oblivion"},
+			expected: 3301,
+		},
+		{
+			name:     "unrelated artist in track name",
+			fixture:  "search_unrelated.json",
+			query:    lyrics.Query{Track: "Crystal Castles - Kerosene"},
+			expected: 0,
+		},
+		{
+			name:     "duration mismatch",
+			fixture:  "search_duration.json",
+			query:    lyrics.Query{Artist: "The Weeknd", Track: "Blinding Lights", Duration: 230000},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Best(loadFixture(t, tt.fixture), tt.query)
+
+			var id int
+			if result != nil {
+				id = result.ID
+			}
+			if id != tt.expected {
+				t.Errorf("Best() = %d; want %d", id, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"Kerosene", "kerosene", 1},
+		{"Kerosene", "Kerosene (Karaoke Version)", 0.5},
+		{"No Love", "Love", 2.0 / 3},
+		{"", "Love", 0},
+	}
+
+	for _, tt := range tests {
+		if result := similarity(tt.a, tt.b); result != tt.expected {
+			t.Errorf("similarity(%q, %q) = %v; want %v", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func loadFixture(t *testing.T, name string) []Record {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tracks []Record
+	if err := json.Unmarshal(data, &tracks); err != nil {
+		t.Fatal(err)
+	}
+	return tracks
+}
@@ -0,0 +1,166 @@
+package lrclibdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/normalize"
+	"github.com/raitonoberu/sptlrx/services/lrclib"
+)
+
+// searchLimit is the maximum number of search results to rank.
+const searchLimit = 20
+
+const columns = `t.name, t.artist_name, COALESCE(t.album_name, ''),
+	COALESCE(t.duration, 0), COALESCE(l.instrumental, 0),
+	COALESCE(l.plain_lyrics, ''), COALESCE(l.synced_lyrics, '')`
+
+// New opens the LRCLIB database dump at path in read-only mode.
+func New(path string) (*Client, error) {
+	if driver == "" {
+		return nil, errors.New("built without SQLite support")
+	}
+	if strings.HasPrefix(path, "~/") {
+		dirname, _ := os.UserHomeDir()
+		path = filepath.Join(dirname, path[2:])
+	}
+	// sql.Open doesn't fail if the file doesn't exist
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	u := url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}
+	db, err := sql.Open(driver, u.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Client{db: db}, nil
+}
+
+// Client implements lyrics.Provider
+type Client struct {
+	db *sql.DB
+}
+
+// Close closes the database.
+func (c *Client) Close() error {
+	return c.db.Close()
+}
+
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	if query.Artist != "" && query.Track != "" {
+		result, err := c.get(ctx, query)
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	return c.search(ctx, query)
+}
+
+// get looks for the track with exactly the same names.
+func (c *Client) get(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	records, err := c.query(ctx, `SELECT `+columns+`
+		FROM tracks t JOIN lyrics l ON l.id = t.last_lyrics_id
+		WHERE t.name_lower = ? AND t.artist_name_lower = ?
+		ORDER BY t.album_name_lower = ? DESC, t.id`,
+		prepare(query.Track), prepare(query.Artist), prepare(query.Album),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if r := lrclib.Best(records, query); r != nil {
+		return lrclib.ParseRecord(*r), nil
+	}
+	return nil, nil
+}
+
+// search performs full-text search. If the dump has no index
+// or SQLite is built without FTS5, the tracks are scanned instead.
+func (c *Client) search(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	words := lrclib.SearchWords(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	match := make([]string, len(words))
+	for i, w := range words {
+		match[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	records, err := c.query(ctx, `SELECT `+columns+`
+		FROM tracks_fts f
+		JOIN tracks t ON t.id = f.rowid
+		JOIN lyrics l ON l.id = t.last_lyrics_id
+		WHERE tracks_fts MATCH ?
+		ORDER BY f.rank LIMIT ?`,
+		strings.Join(match, " "), searchLimit,
+	)
+	if err != nil && ctx.Err() == nil {
+		records, err = c.scan(ctx, words)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r := lrclib.Best(records, query); r != nil {
+		return lrclib.ParseRecord(*r), nil
+	}
+	return nil, nil
+}
+
+// scan returns tracks that contain all the words in their names.
+func (c *Client) scan(ctx context.Context, words []string) ([]lrclib.Record, error) {
+	where := make([]string, len(words))
+	args := make([]any, 0, len(words)+1)
+	for i, w := range words {
+		where[i] = `(t.artist_name_lower || ' ' || t.name_lower) LIKE ?`
+		args = append(args, "%"+w+"%")
+	}
+	args = append(args, searchLimit)
+
+	return c.query(ctx, `SELECT `+columns+`
+		FROM tracks t JOIN lyrics l ON l.id = t.last_lyrics_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY t.id LIMIT ?`,
+		args...,
+	)
+}
+
+func (c *Client) query(ctx context.Context, query string, args ...any) ([]lrclib.Record, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []lrclib.Record
+	for rows.Next() {
+		var r lrclib.Record
+		err := rows.Scan(
+			&r.TrackName, &r.ArtistName, &r.AlbumName,
+			&r.Duration, &r.Instrumental,
+			&r.PlainLyrics, &r.SyncedLyrics,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// prepare normalizes the name the same way LRCLIB does
+// for the *_lower columns.
+func prepare(s string) string {
+	return normalize.StripPunct(normalize.Fold(s))
+}
@@ -0,0 +1,188 @@
+package lrclibdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+)
+
+const schema = `
+CREATE TABLE tracks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT, name_lower TEXT,
+	artist_name TEXT, artist_name_lower TEXT,
+	album_name TEXT, album_name_lower TEXT,
+	duration FLOAT,
+	last_lyrics_id INTEGER
+);
+CREATE TABLE lyrics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	plain_lyrics TEXT,
+	synced_lyrics TEXT,
+	track_id INTEGER,
+	has_plain_lyrics BOOLEAN,
+	has_synced_lyrics BOOLEAN,
+	instrumental BOOLEAN
+);`
+
+// tracks_fts is only available if SQLite is built with FTS5
+const ftsSchema = `
+CREATE VIRTUAL TABLE tracks_fts USING fts5(
+	name_lower, album_name_lower, artist_name_lower,
+	content='tracks', content_rowid='id'
+);
+INSERT INTO tracks_fts(tracks_fts) VALUES ('rebuild');`
+
+type track struct {
+	name, artist, album string
+	// the *_lower columns as LRCLIB fills them
+	nameLower, artistLower, albumLower string
+	duration                           float64
+	instrumental                       bool
+	plain, synced                      string
+}
+
+var tracks = []track{
+	{
+		"Kerosene", "Crystal Castles", "Crystal Castles (II)",
+		"kerosene", "crystal castles", "crystal castles ii",
+		197, false, "Take me down", "",
+	},
+	{
+		"Kerosene", "Crystal Castles", "Crystal Castles",
+		"kerosene", "crystal castles", "crystal castles",
+		197, false, "Take me down", "[00:10.12] Take me down",
+	},
+	{
+		"Kerosene (Karaoke Version)", "Karaoke Hits Band", "Karaoke Hits",
+		"kerosene karaoke version", "karaoke hits band", "karaoke hits",
+		198, false, "Take me down", "[00:01.00] Take me down",
+	},
+	{
+		"Genesis", "Grimes", "Visions",
+		"genesis", "grimes", "visions",
+		255, true, "", "",
+	},
+	{
+		"Don't Stop Me Now", "Queen", "Jazz",
+		"dont stop me now", "queen", "jazz",
+		209, false, "", "[00:01.00] Tonight",
+	},
+}
+
+func createDump(t *testing.T) string {
+	if driver == "" {
+		t.Skip("built without SQLite support")
+	}
+
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	for i, tr := range tracks {
+		id := i + 1
+		_, err := db.Exec(`INSERT INTO lyrics (id, plain_lyrics, synced_lyrics, track_id, instrumental)
+			VALUES (?, NULLIF(?, ''), NULLIF(?, ''), ?, ?)`,
+			id, tr.plain, tr.synced, id, tr.instrumental,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.Exec(`INSERT INTO tracks (id, name, name_lower, artist_name, artist_name_lower,
+			album_name, album_name_lower, duration, last_lyrics_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, tr.name, tr.nameLower, tr.artist, tr.artistLower,
+			tr.album, tr.albumLower, tr.duration, id,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(ftsSchema); err != nil {
+		t.Log("full-text search is not available:", err)
+	}
+	return path
+}
+
+func TestLyrics(t *testing.T) {
+	client, err := New(createDump(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	tests := []struct {
+		query    lyrics.Query
+		expected []lyrics.Line
+	}{
+		{
+			// exact match, synced lyrics are preferred
+			query:    lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene"},
+			expected: []lyrics.Line{{Time: 10120, Words: "Take me down"}},
+		},
+		{
+			query:    lyrics.Query{Artist: "Crystal Castles", Track: "Kerosene", Duration: 240000},
+			expected: nil,
+		},
+		{
+			// names are normalized
+			query:    lyrics.Query{Artist: "QUEEN", Track: "Dont Stop Me Now!"},
+			expected: []lyrics.Line{{Time: 1000, Words: "Tonight"}},
+		},
+		{
+			query:    lyrics.Query{Track: "Crystal Castles - Kerosene"},
+			expected: []lyrics.Line{{Time: 10120, Words: "Take me down"}},
+		},
+		{
+			query:    lyrics.Query{Artist: "Grimes", Track: "Genesis"},
+			expected: []lyrics.Line{{}},
+		},
+		{
+			query:    lyrics.Query{Artist: "Grimes", Track: "Oblivion"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := client.Lyrics(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("Lyrics(%+v): %v", tt.query, err)
+		}
+		var lines []lyrics.Line
+		if result != nil {
+			lines = result.Lines
+		}
+		if !reflect.DeepEqual(lines, tt.expected) {
+			t.Errorf("Lyrics(%+v) = %+v; want %+v", tt.query, lines, tt.expected)
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Kerosene", "kerosene"},
+		{"  Don't   Stop Me Now ", "dont stop me now"},
+		{"Crystal Castles (II)", "crystal castles ii"},
+		{"AC/DC", "ac dc"},
+		{"Beyoncé", "beyonce"},
+	}
+
+	for _, tt := range tests {
+		if result := prepare(tt.input); result != tt.expected {
+			t.Errorf("prepare(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
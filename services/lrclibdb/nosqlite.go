@@ -0,0 +1,6 @@
+//go:build !cgo
+
+package lrclibdb
+
+// SQLite driver requires cgo
+const driver = ""
@@ -0,0 +1,7 @@
+//go:build cgo
+
+package lrclibdb
+
+import _ "github.com/mattn/go-sqlite3"
+
+const driver = "sqlite3"
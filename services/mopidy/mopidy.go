@@ -2,10 +2,12 @@ package mopidy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/raitonoberu/sptlrx/player"
 	"net/http"
+	"strings"
 )
 
 func New(address string) *Client {
@@ -15,9 +17,10 @@ func New(address string) *Client {
 // Client implements player.Player
 type Client struct {
 	address string
+	http    http.Client
 }
 
-func (c *Client) get(method string, out interface{}) error {
+func (c *Client) get(ctx context.Context, method string, out interface{}) error {
 	body := requestBody{
 		JsonRPC: "2.0",
 		ID:      1,
@@ -29,13 +32,13 @@ func (c *Client) get(method string, out interface{}) error {
 	}
 
 	url := fmt.Sprintf("http://%s/mopidy/rpc", c.address)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return err
 	}
@@ -44,21 +47,21 @@ func (c *Client) get(method string, out interface{}) error {
 	return json.NewDecoder(resp.Body).Decode(out)
 }
 
-func (c *Client) State() (*player.State, error) {
+func (c *Client) State(ctx context.Context) (*player.State, error) {
 	var state stateResponse
-	err := c.get("core.playback.get_state", &state)
+	err := c.get(ctx, "core.playback.get_state", &state)
 	if err != nil {
 		return nil, err
 	}
 
 	var current currentResponse
-	err = c.get("core.playback.get_current_track", &current)
+	err = c.get(ctx, "core.playback.get_current_track", &current)
 	if err != nil {
 		return nil, err
 	}
 
 	var position positionResponse
-	err = c.get("core.playback.get_time_position", &position)
+	err = c.get(ctx, "core.playback.get_time_position", &position)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +74,21 @@ func (c *Client) State() (*player.State, error) {
 		artist += a.Name
 	}
 
+	// only local files can be used for lyrics lookup
+	var file string
+	if strings.HasPrefix(current.Result.URI, "file:") {
+		file = current.Result.URI
+	}
+
 	return &player.State{
 		ID:       current.Result.URI,
 		Artist:   artist,
 		Track:    current.Result.Name,
+		Album:    current.Result.Album.Name,
+		Duration: current.Result.Length,
 		Position: position.Result,
 		Playing:  state.Result == "playing",
+		File:     file,
 	}, err
 }
 
@@ -90,9 +102,13 @@ type currentResponse struct {
 	Result struct {
 		URI     string `json:"uri"`
 		Name    string `json:"name"`
+		Length  int    `json:"length"`
 		Artists []struct {
 			Name string `json:"name"`
 		} `json:"artists"`
+		Album struct {
+			Name string `json:"name"`
+		} `json:"album"`
 	} `json:"result"`
 }
 
@@ -1,6 +1,7 @@
 package mpd
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/raitonoberu/sptlrx/player"
@@ -22,36 +23,60 @@ type Client struct {
 	client   *mpd.Client
 }
 
-func (c *Client) connect() error {
-	if c.client != nil {
-		c.client.Close()
+// State gives up on the client if it doesn't respond in time,
+// since gompd doesn't support cancellation.
+func (c *Client) State(ctx context.Context) (*player.State, error) {
+	type result struct {
+		client *mpd.Client
+		state  *player.State
+		err    error
 	}
-	client, err := mpd.DialAuthenticated("tcp", c.address, c.password)
-	if err != nil {
-		c.client = nil
-		return err
-	}
-	c.client = client
-	return nil
-}
+	client := c.client
+	c.client = nil
 
-func (c *Client) checkConnection() error {
-	if c.client == nil || c.client.Ping() != nil {
-		return c.connect()
+	ch := make(chan result, 1)
+	go func() {
+		client, err := c.connect(client)
+		if err != nil {
+			ch <- result{err: err}
+			return
+		}
+		state, err := getState(client)
+		ch <- result{client, state, err}
+	}()
+
+	select {
+	case r := <-ch:
+		c.client = r.client
+		return r.state, r.err
+	case <-ctx.Done():
+		go func() {
+			// close the connection once it's done
+			if r := <-ch; r.client != nil {
+				r.client.Close()
+			}
+		}()
+		return nil, ctx.Err()
 	}
-	return nil
 }
 
-func (c *Client) State() (*player.State, error) {
-	if err := c.checkConnection(); err != nil {
-		return nil, err
+// connect returns the client if it's still connected, or a new one.
+func (c *Client) connect(client *mpd.Client) (*mpd.Client, error) {
+	if client != nil {
+		if client.Ping() == nil {
+			return client, nil
+		}
+		client.Close()
 	}
+	return mpd.DialAuthenticated("tcp", c.address, c.password)
+}
 
-	status, err := c.client.Status()
+func getState(client *mpd.Client) (*player.State, error) {
+	status, err := client.Status()
 	if err != nil {
 		return nil, err
 	}
-	current, err := c.client.CurrentSong()
+	current, err := client.CurrentSong()
 	if err != nil {
 		return nil, err
 	}
@@ -67,11 +92,20 @@ func (c *Client) State() (*player.State, error) {
 		artist = a
 	}
 
+	duration, err := strconv.ParseFloat(status["duration"], 32)
+	if err != nil {
+		// older versions only have the integer "Time"
+		duration, _ = strconv.ParseFloat(current["Time"], 32)
+	}
+
 	return &player.State{
 		ID:       status["songid"],
 		Artist:   artist,
 		Track:    title,
+		Album:    current["Album"],
+		Duration: int(duration * 1000), // secs to ms
 		Playing:  status["state"] == "play",
 		Position: int(elapsed * 1000), // secs to ms
+		File:     current["file"],
 	}, nil
 }
@@ -3,6 +3,7 @@
 package mpris
 
 import (
+	"context"
 	"github.com/raitonoberu/sptlrx/player"
 	"net/url"
 	"path/filepath"
@@ -53,7 +54,28 @@ func (c *Client) getPlayer() (*mpris.Player, error) {
 	return nil, nil
 }
 
-func (c *Client) State() (*player.State, error) {
+// State gives up waiting if the player doesn't respond in time,
+// since go-mpris doesn't support cancellation.
+func (c *Client) State(ctx context.Context) (*player.State, error) {
+	type result struct {
+		state *player.State
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		state, err := c.state()
+		ch <- result{state, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.state, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) state() (*player.State, error) {
 	p, err := c.getPlayer()
 	if err != nil {
 		return nil, err
@@ -82,7 +104,9 @@ func (c *Client) State() (*player.State, error) {
 	}
 
 	// In case the player uses the file name with extension as title
+	var file string
 	if u, ok := meta["xesam:url"].Value().(string); ok {
+		file = u
 		u, err := url.Parse(u)
 		if err == nil {
 			ext := filepath.Ext(u.Path)
@@ -98,13 +122,31 @@ func (c *Client) State() (*player.State, error) {
 		artist = strings.Join(a.([]string), " ")
 	}
 
+	var album string
+	if a, ok := meta["xesam:album"].Value().(string); ok {
+		album = a
+	}
+
+	var duration int
+	switch l := meta["mpris:length"].Value().(type) {
+	case int64:
+		duration = int(l / 1000) // us to ms
+	case uint64:
+		duration = int(l / 1000)
+	case int32:
+		duration = int(l / 1000)
+	}
+
 	id := artist + " " + title
 
 	return &player.State{
 		ID:       id, // use artist+title as id since mpris:trackid is broken
 		Artist:   artist,
 		Track:    title,
+		Album:    album,
+		Duration: duration,
 		Position: int(position * 1000), // secs to ms
 		Playing:  status == mpris.PlaybackPlaying,
+		File:     file,
 	}, err
 }
@@ -3,6 +3,7 @@
 package mpris
 
 import (
+	"context"
 	"errors"
 	"github.com/raitonoberu/sptlrx/player"
 )
@@ -14,6 +15,6 @@ func New(players []string) (*Client, error) {
 // Client implements player.Player
 type Client struct{}
 
-func (p *Client) State() (*player.State, error) {
+func (p *Client) State(ctx context.Context) (*player.State, error) {
 	return nil, nil
 }
@@ -0,0 +1,62 @@
+package sidecar
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/embedded"
+	"github.com/raitonoberu/sptlrx/services/local"
+)
+
+// New returns a provider that looks for lyrics next to the file being
+// played (song.mp3 -> song.lrc) or embedded in it. Relative paths are
+// resolved against musicDir.
+func New(musicDir string) *Client {
+	if strings.HasPrefix(musicDir, "~/") {
+		dirname, _ := os.UserHomeDir()
+		musicDir = filepath.Join(dirname, musicDir[2:])
+	}
+	return &Client{
+		musicDir: musicDir,
+		embedded: embedded.New(musicDir),
+	}
+}
+
+// Client implements lyrics.Provider
+type Client struct {
+	musicDir string
+	embedded *embedded.Client
+}
+
+func (c *Client) Lyrics(ctx context.Context, query lyrics.Query) (*lyrics.Lyrics, error) {
+	path := embedded.LocalPath(c.musicDir, query.File)
+	if path == "" {
+		return nil, nil
+	}
+	result, err := c.find(path)
+	if err != nil || result != nil {
+		return result, err
+	}
+
+	// broken tags shouldn't prevent using the fallback
+	result, _ = c.embedded.Lyrics(ctx, query)
+	return result, nil
+}
+
+// find returns the lyrics file next to the audio file, if any.
+func (c *Client) find(path string) (*lyrics.Lyrics, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range local.Extensions {
+		result, err := local.ReadFile(base + ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return result, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,61 @@
+package sidecar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/raitonoberu/sptlrx/lyrics"
+	"github.com/raitonoberu/sptlrx/services/local"
+)
+
+func TestLyrics(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "Artist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"Artist/song.mp3":  "",
+		"Artist/song.lrc":  "[00:01.00]sidecar",
+		"Artist/other.mp3": "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := New(dir)
+
+	tests := []struct {
+		name     string
+		file     string
+		expected []lyrics.Line
+	}{
+		{"relative", "Artist/song.mp3", []lyrics.Line{{Time: 1000, Words: "sidecar"}}},
+		{"uri", "file://" + filepath.ToSlash(filepath.Join(dir, "Artist", "song.mp3")), []lyrics.Line{{Time: 1000, Words: "sidecar"}}},
+		{"no sidecar", "Artist/other.mp3", nil},
+		{"stream", "http://example.com/song.mp3", nil},
+		{"unknown", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Lyrics(context.Background(), lyrics.Query{File: tt.file})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lines []lyrics.Line
+			if result != nil {
+				lines = result.Lines
+				if url := local.FileURL(filepath.Join(dir, "Artist", "song.lrc")); result.URL != url {
+					t.Errorf("Lyrics(%q).URL = %q; want %q", tt.file, result.URL, url)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("Lyrics(%q) = %+v; want %+v", tt.file, lines, tt.expected)
+			}
+		})
+	}
+}
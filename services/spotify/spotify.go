@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/raitonoberu/sptlrx/player"
 	"github.com/raitonoberu/sptlrx/services/spotify/auth"
@@ -34,10 +33,7 @@ type Client struct {
 	http http.Client
 }
 
-func (c *Client) State() (*player.State, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (c *Client) State(ctx context.Context) (*player.State, error) {
 	token, err := c.auth.GetToken(ctx)
 	if err != nil {
 		return nil, err
@@ -77,6 +73,8 @@ func (c *Client) State() (*player.State, error) {
 		ID:       state.Item.ID,
 		Artist:   b.String(),
 		Track:    state.Item.Name,
+		Album:    state.Item.Album.Name,
+		Duration: state.Item.DurationMs,
 		Position: state.ProgressMs,
 		Playing:  state.IsPlaying,
 	}, nil
@@ -89,11 +87,17 @@ type state struct {
 }
 
 type track struct {
-	ID      string        `json:"id"`
-	Name    string        `json:"name"`
-	Artists []trackArtist `json:"artists"`
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	DurationMs int           `json:"duration_ms"`
+	Artists    []trackArtist `json:"artists"`
+	Album      trackAlbum    `json:"album"`
 }
 
 type trackArtist struct {
 	Name string `json:"name"`
 }
+
+type trackAlbum struct {
+	Name string `json:"name"`
+}
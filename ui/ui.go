@@ -2,11 +2,11 @@ package ui
 
 import (
 	"github.com/raitonoberu/sptlrx/config"
-	"github.com/raitonoberu/sptlrx/lyrics"
 	"github.com/raitonoberu/sptlrx/pool"
 	"os"
 	"runtime"
 	"strings"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	gloss "github.com/charmbracelet/lipgloss"
@@ -19,6 +19,8 @@ type Model struct {
 
 	state pool.Update
 	w, h  int
+	// whether to show where the lyrics came from
+	showSource bool
 
 	styleBefore  gloss.Style
 	styleCurrent gloss.Style
@@ -78,22 +80,27 @@ func (m *Model) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 				m.hAlignment = 1
 			}
 
+		case "s":
+			m.showSource = !m.showSource
+
 		case "up":
-			if m.state.Playing && lyrics.Timesynced(m.state.Lines) {
+			if m.state.Playing && m.synced() {
 				break
 			}
 			m.state.Index -= 1
 			if m.state.Index < 0 {
 				m.state.Index = 0
 			}
+			m.state.Progress = 0
 		case "down":
-			if m.state.Playing && lyrics.Timesynced(m.state.Lines) {
+			if m.state.Playing && m.synced() {
 				break
 			}
 			m.state.Index += 1
 			if m.state.Index >= len(m.state.Lines) {
 				m.state.Index = len(m.state.Lines) - 1
 			}
+			m.state.Progress = 0
 		}
 	}
 	return m, cmd
@@ -103,28 +110,55 @@ func (m *Model) View() string {
 	if m.w < 1 || m.h < 1 {
 		return ""
 	}
+	source := m.renderSource()
+	if source == "" || m.h < 2 {
+		return m.view(m.h)
+	}
+	return gloss.JoinVertical(
+		m.hAlignment,
+		gloss.PlaceVertical(m.h-1, gloss.Top, m.view(m.h-1)),
+		source,
+	)
+}
+
+func (m *Model) view(h int) string {
 	if m.state.Err != nil && !m.Config.IgnoreErrors {
 		return gloss.PlaceVertical(
-			m.h, gloss.Center,
+			h, gloss.Center,
 			m.styleCurrent.
 				Align(gloss.Center).
 				Width(m.w).
 				Render(m.state.Err.Error()),
 		)
 	}
+	if m.state.Instrumental {
+		return gloss.PlaceVertical(
+			h, gloss.Center,
+			m.styleCurrent.
+				Align(m.hAlignment).
+				Width(m.w).
+				Render(m.Config.InstrumentalText),
+		)
+	}
+	if m.state.Loading {
+		return gloss.PlaceVertical(
+			h, gloss.Center,
+			m.styleAfter.
+				Align(m.hAlignment).
+				Width(m.w).
+				Render("Loading..."),
+		)
+	}
 	if len(m.state.Lines) == 0 {
 		return ""
 	}
 
-	curLine := m.styleCurrent.
-		Width(m.w).
-		Align(m.hAlignment).
-		Render(m.state.Lines[m.state.Index].Words)
+	curLine := m.renderCurrent()
 	curLines := strings.Split(curLine, "\n")
 
 	curLen := len(curLines)
-	beforeLen := (m.h - curLen) / 2
-	afterLen := m.h - beforeLen - curLen
+	beforeLen := (h - curLen) / 2
+	afterLen := h - beforeLen - curLen
 
 	lines := make([]string, beforeLen+curLen+afterLen)
 
@@ -188,8 +222,81 @@ func (m *Model) View() string {
 	return gloss.JoinVertical(m.hAlignment, lines...)
 }
 
+// renderCurrent renders the current line, highlighting
+// the syllables that have been sung if there are any.
+func (m *Model) renderCurrent() string {
+	line := m.state.Lines[m.state.Index]
+	progress := m.state.Progress
+	if progress <= 0 || len(line.Syllables) == 0 {
+		return m.styleCurrent.
+			Width(m.w).
+			Align(m.hAlignment).
+			Render(line.Words)
+	}
+
+	var sung, rest strings.Builder
+	for i, s := range line.Syllables {
+		if i < progress {
+			sung.WriteString(s.Text)
+		} else {
+			rest.WriteString(s.Text)
+		}
+	}
+
+	sungText := strings.TrimLeftFunc(sung.String(), unicode.IsSpace)
+	restText := strings.TrimRightFunc(rest.String(), unicode.IsSpace)
+	if sungText == "" {
+		restText = strings.TrimLeftFunc(restText, unicode.IsSpace)
+	}
+	if restText == "" {
+		sungText = strings.TrimRightFunc(sungText, unicode.IsSpace)
+	}
+
+	var words string
+	if sungText != "" {
+		words += m.styleCurrent.Render(sungText)
+	}
+	if restText != "" {
+		words += m.styleAfter.Render(restText)
+	}
+	return gloss.NewStyle().
+		Width(m.w).
+		Align(m.hAlignment).
+		Render(words)
+}
+
+// renderSource renders the name of the provider and the track
+// the lyrics were found for, if it's enabled.
+func (m *Model) renderSource() string {
+	l := m.state.Lyrics
+	if !m.showSource || l == nil || l.Source == "" {
+		return ""
+	}
+
+	parts := []string{l.Source}
+	if l.Artist != "" && l.Title != "" {
+		parts = append(parts, l.Artist+" - "+l.Title)
+	}
+	if !l.Synced && !m.state.Instrumental {
+		parts = append(parts, "not synced")
+	}
+	return m.styleAfter.
+		Width(m.w).
+		MaxHeight(1).
+		Align(m.hAlignment).
+		Render(strings.Join(parts, " · "))
+}
+
+func (m *Model) synced() bool {
+	return m.state.Lyrics != nil && m.state.Lyrics.Synced
+}
+
 func waitForUpdate(ch chan pool.Update) tea.Cmd {
 	return func() tea.Msg {
-		return <-ch
+		update, ok := <-ch
+		if !ok {
+			return tea.Quit()
+		}
+		return update
 	}
 }